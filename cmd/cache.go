@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"repo-doc/internal/cache"
+
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear the local response cache",
+	Long: `Manage the on-disk cache stored under $XDG_CACHE_HOME/repo-doc.
+
+GitHub responses are cached with their ETag and revalidated on the next run,
+so unchanged data is served locally and does not use up your rate limit.`,
+	Example: `  repo-doc cache stats
+  repo-doc cache clear`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cache location, entry count and size",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, sections, err := cache.Stats()
+		if err != nil {
+			log.Fatalf("Error reading cache: %v", err)
+		}
+
+		fmt.Printf("📁 Location: %s\n", dir)
+		if len(sections) == 0 {
+			fmt.Println("🗑️  Cache is empty")
+			return
+		}
+
+		var entries int
+		var size int64
+		for _, s := range sections {
+			fmt.Printf("   %-12s %6d entries  %s\n", s.Name, s.Entries, formatBytes(s.Bytes))
+			entries += s.Entries
+			size += s.Bytes
+		}
+		fmt.Printf("📦 Total:      %d entries, %s\n", entries, formatBytes(size))
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all cached data",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, err := cache.Clear()
+		if err != nil {
+			log.Fatalf("Error clearing cache: %v", err)
+		}
+		fmt.Printf("🧹 Cleared %s\n", dir)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -2,243 +2,203 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
-	"os"
-	"regexp"
 	"strings"
-	"time"
 
-	"github.com/google/generative-ai-go/genai"
 	"github.com/spf13/cobra"
-	"google.golang.org/api/option"
 
 	"repo-doc/internal/analyzer"
+	"repo-doc/internal/cache"
+	"repo-doc/internal/sentiment"
 )
 
 var (
-	healthLimit int
+	healthLimit       int
+	sentimentProvider string
+	sentimentModel    string
+	sentimentURL      string
+	healthBatchSize   int
 )
 
-type SentimentResponse struct {
-	Sentiment string  `json:"sentiment"`
-	Score     float64 `json:"score"`
-}
-
-type HealthReport struct {
-	PRCount          int
-	MessageCount     int
-	PositiveScore    float64
-	NegativeScore    float64
-	NeutralScore     float64
-	AverageSentiment float64
-	Messages         []MessageAnalysis
-}
-
-type MessageAnalysis struct {
-	Content   string
-	Sentiment string
-	Score     float64
-}
-
 var healthCmd = &cobra.Command{
 	Use:   "health [owner/repo or URL]",
 	Short: "Analyze PR health using sentiment analysis",
 	Long: `Analyze the health of pull requests using sentiment analysis.
 
 This command analyzes the sentiment of PR discussions to provide
-insights into the overall health and tone of the project's PRs.`,
-	Args: cobra.ExactArgs(1),
+insights into the overall health and tone of the project's PRs.
+
+Without an argument, the repository of the git checkout in the current
+directory is used.`,
+	Args: cobra.MaximumNArgs(1),
 	Run:  runHealthAnalysis,
 	Example: `  # Analyze health of last 5 PRs
   repo-doc health golang/go
 
-  # Analyze specific number of PRs
-  repo-doc health golang/go --limit 10
-
-  # Using full GitHub URL
-  repo-doc health https://github.com/golang/go`,
-}
+  # Analyze the repository in the current directory
+  repo-doc health
 
-func init() {
-	rootCmd.AddCommand(healthCmd)
+  # Sentiment of January's messages in the last 20 PRs
+  repo-doc health golang/go --limit 20 --since 2024-01-01 --until 2024-01-31
 
-	healthCmd.Flags().IntVarP(&healthLimit, "limit", "l", 5,
-		`Number of most recent PRs to analyze (max 20).`)
-}
+  # Weekly report without the log output
+  repo-doc health golang/go --since 7d -o health.txt --force
 
-func runHealthAnalysis(cmd *cobra.Command, args []string) {
-	if os.Getenv("GEMINI_API_KEY") == "" {
-		log.Fatal("GEMINI_API_KEY environment variable is required for health analysis. Please set it in .env file or environment variables")
-	}
+  # Dashboard with charts and threads to share with people who don't use the CLI
+  repo-doc health golang/go --limit 20 -o health.html
 
-	repoURL := args[0]
+  # Analyze specific number of PRs
+  repo-doc health golang/go --limit 10
 
-	owner, repo, err := analyzer.ParseRepoURL(repoURL)
-	if err != nil {
-		log.Fatalf("Error parsing repository URL: %v", err)
-	}
+  # Using full GitHub URL
+  repo-doc health https://github.com/golang/go
 
-	if healthLimit < 1 || healthLimit > 20 {
-		healthLimit = 5
-	}
+  # Analyze a single PR
+  repo-doc health https://github.com/golang/go/pull/74251
 
-	a := analyzer.New(token)
+  # Offline analysis without a Gemini API key
+  repo-doc health golang/go --sentiment-provider lexicon
 
-	discussions, err := a.FetchPRDiscussions(owner, repo, healthLimit)
-	if err != nil {
-		log.Fatalf("Error fetching PR discussions: %v", err)
-	}
+  # Self-hosted models: nothing leaves your network
+  repo-doc health golang/go --sentiment-provider ollama --sentiment-model llama3
+  repo-doc health golang/go --sentiment-provider openai --sentiment-url http://llm.internal:8000 --sentiment-model qwen2.5
 
-	report := analyzePRHealth(discussions)
-	displayHealthReport(report)
+  # Choose the sentiment backend and model
+  repo-doc health golang/go --sentiment-provider gemini --sentiment-model gemini-1.5-flash`,
 }
 
-func cleanTextForAnalysis(text string) string {
-	// Remove code blocks
-	re := regexp.MustCompile("(?s)```.*?```")
-	text = re.ReplaceAllString(text, " ")
-
-	// Remove inline code
-	re = regexp.MustCompile("`[^`]+`")
-	text = re.ReplaceAllString(text, " ")
-
-	// Remove URLs
-	re = regexp.MustCompile(`https?://\S+`)
-	text = re.ReplaceAllString(text, " ")
-
-	// Remove markdown headers, lists, etc.
-	re = regexp.MustCompile(`[#*\-_=~]+`)
-	text = re.ReplaceAllString(text, " ")
+func init() {
+	rootCmd.AddCommand(healthCmd)
+	addRemoteFlag(healthCmd)
+	addTimeRangeFlags(healthCmd)
+	addFormatFlag(healthCmd)
+	addOutputFlags(healthCmd)
 
-	// Remove extra whitespace
-	text = strings.Join(strings.Fields(text), " ")
+	healthCmd.Flags().IntVarP(&healthLimit, "limit", "l", 5,
+		`Number of most recent PRs to analyze.`)
+	addSentimentFlags(healthCmd)
+}
 
-	return strings.TrimSpace(text)
+// addSentimentFlags registers the flags that select the sentiment backend.
+func addSentimentFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&sentimentProvider, "sentiment-provider", "auto",
+		`Sentiment backend used to classify messages.
+Available options:
+  auto    - gemini if GEMINI_API_KEY is set, otherwise lexicon (default)
+  gemini  - Google Gemini API (requires GEMINI_API_KEY)
+  lexicon - Built-in offline analyzer tuned for code review language
+  openai  - Any OpenAI-compatible /v1/chat/completions server (needs --sentiment-url)
+  ollama  - Ollama /api/generate server (default http://localhost:11434)`)
+	cmd.Flags().StringVar(&sentimentModel, "sentiment-model", "",
+		`Model name passed to the sentiment backend (provider default if empty).`)
+	cmd.Flags().StringVar(&sentimentURL, "sentiment-url", "",
+		`Base URL of a self-hosted openai or ollama sentiment server.
+Set OPENAI_API_KEY if the openai-compatible server requires a bearer token.`)
+	cmd.Flags().IntVar(&healthBatchSize, "batch-size", sentiment.DefaultBatchSize,
+		`Number of messages classified per LLM request.
+Use 1 to send every message separately.`)
 }
 
-func analyzeWithGemini(ctx context.Context, text string) (string, float64, error) {
-	cleanText := cleanTextForAnalysis(text)
-	if cleanText == "" {
-		return "neutral", 0.5, nil
-	}
+func runHealthAnalysis(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
 
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	if apiKey == "" {
-		return "", 0, fmt.Errorf("GEMINI_API_KEY environment variable not set")
-	}
+	backend := newSentimentBackend(ctx)
+	defer backend.Close()
 
-	clientCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
+	ref := resolveRepo(args)
+	start, end := timeRange()
+	outputManager := newOutputManager(cmd)
 
-	client, err := genai.NewClient(clientCtx, option.WithAPIKey(apiKey))
-	if err != nil {
-		return "", 0, fmt.Errorf("failed to create Gemini client: %v", err)
+	if healthLimit < 1 {
+		healthLimit = 5
 	}
-	defer client.Close()
-
-	model := client.GenerativeModel("gemini-1.5-pro-latest")
-
-	temp := float32(0.2)
-	topP := float32(0.9)
-	topK := int32(40)
-	maxTokens := int32(1024)
-
-	model.Temperature = &temp
-	model.TopP = &topP
-	model.TopK = &topK
-	model.MaxOutputTokens = &maxTokens
-
-	prompt := fmt.Sprintf(`Analyze the sentiment of this GitHub PR discussion text and respond with a JSON object containing "sentiment" (one of: "positive", "neutral", "negative") and "score" (0.0 to 1.0, where 0 is most negative and 1 is most positive).
 
-Text to analyze:
-%s
+	a := newAnalyzer()
 
-Respond with only the JSON object, nothing else.`, cleanText)
+	discussions := fetchDiscussions(ctx, a, ref, healthLimit)
+	discussions = analyzer.FilterByTime(discussions, start, end)
+	addRepoContext(ctx, a, outputManager, ref)
 
-	log.Printf("Sending request to model with prompt length: %d", len(prompt))
-	resp, err := model.GenerateContent(clientCtx, genai.Text(prompt))
-	if err != nil {
-		log.Printf("Error details: %v", err)
-		return "", 0, fmt.Errorf("failed to generate content: %v", err)
-	}
-
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return "", 0, fmt.Errorf("no content in response")
+	report := analyzePRHealth(ctx, backend, discussions)
+	if c, ok := backend.(*sentiment.Cached); ok {
+		hits, misses := c.Stats()
+		log.Printf("Sentiment cache: %d cached, %d newly classified", hits, misses)
 	}
-	responseText := ""
-	for _, part := range resp.Candidates[0].Content.Parts {
-		if textPart, ok := part.(genai.Text); ok {
-			responseText += string(textPart)
-		}
-	}
-
-	log.Printf("Raw response: %s", responseText)
-
-	var result struct {
-		Sentiment string  `json:"sentiment"`
-		Score     float64 `json:"score"`
+	if err := outputManager.DisplayHealth(report, discussions); err != nil {
+		log.Fatalf("Error displaying output: %v", err)
 	}
+	reportWritten(outputManager)
+}
 
-	jsonStart := strings.Index(responseText, "{")
-	jsonEnd := strings.LastIndex(responseText, "}")
-	if jsonStart == -1 || jsonEnd == -1 {
-		return "", 0, fmt.Errorf("invalid JSON response: %s", responseText)
+// newSentimentBackend builds the backend selected by the sentiment flags.
+// LLM results are cached on disk unless --no-cache is set; the lexicon
+// backend is cheaper to rerun than to look up.
+func newSentimentBackend(ctx context.Context) sentiment.Sentiment {
+	backend, err := sentiment.New(ctx, sentiment.Config{
+		Provider: sentimentProvider,
+		Model:    sentimentModel,
+		BaseURL:  sentimentURL,
+		Retries:  retries,
+	})
+	if err != nil {
+		log.Fatalf("Error configuring sentiment backend: %v", err)
 	}
 
-	jsonStr := responseText[jsonStart : jsonEnd+1]
-	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		log.Printf("Failed to parse JSON response: %v\nResponse: %s", err, responseText)
-		return "", 0, fmt.Errorf("failed to parse JSON response: %v", err)
+	if noCache || backend.Name() == "lexicon" {
+		return backend
 	}
 
-	switch result.Sentiment {
-	case "positive", "neutral", "negative":
-	default:
-		return "", 0, fmt.Errorf("invalid sentiment value: %s", result.Sentiment)
-	}
-	if result.Score < 0 || result.Score > 1 {
-		return "", 0, fmt.Errorf("score out of range: %f", result.Score)
+	store, err := cache.Open("sentiment")
+	if err != nil {
+		log.Printf("Warning: sentiment cache disabled: %v", err)
+		return backend
 	}
-
-	log.Printf("Analysis result - Sentiment: %s, Score: %.2f", result.Sentiment, result.Score)
-	return result.Sentiment, result.Score, nil
+	return sentiment.NewCached(backend, store)
 }
 
-func analyzeSentiment(text string) (string, float64) {
-	if text == "" {
-		return "neutral", 0.5
+// analyzePRHealth classifies every human message in discussions. Messages
+// the backend could not classify are counted in FailedCount, and those
+// skipped because ctx was cancelled in InterruptedCount; both are left out
+// of the statistics rather than skewing them as neutral.
+func analyzePRHealth(ctx context.Context, s sentiment.Sentiment, discussions []*analyzer.PRDiscussion) *analyzer.HealthReport {
+	report := &analyzer.HealthReport{
+		PRCount:  len(discussions),
+		Messages: make([]analyzer.MessageAnalysis, 0),
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	sentiment, score, err := analyzeWithGemini(ctx, text)
-	if err != nil {
-		log.Printf("Error analyzing message with Gemini: %v", err)
-		return "neutral", 0.5
+	var items []sentiment.Item
+	for _, d := range discussions {
+		for i, msg := range d.Messages {
+			if msg.Body == "" || isBotComment(msg.Author) {
+				continue
+			}
+			items = append(items, sentiment.Item{
+				ID:   messageID(d.PRNumber, i),
+				Text: msg.Body,
+			})
+		}
 	}
 
-	return sentiment, score
-}
-
-func analyzePRHealth(discussions []*analyzer.PRDiscussion) *HealthReport {
-	report := &HealthReport{
-		PRCount:  len(discussions),
-		Messages: make([]MessageAnalysis, 0),
-	}
+	results, interrupted := sentiment.AnalyzeAll(ctx, s, items, healthBatchSize)
+	report.InterruptedCount = interrupted
 
 	totalScore := 0.0
 	messageCount := 0
+	missing := 0
 
 	for _, d := range discussions {
-		for _, msg := range d.Messages {
+		for i, msg := range d.Messages {
 			if msg.Body == "" || isBotComment(msg.Author) {
 				continue
 			}
 
-			sentimentLabel, score := analyzeSentiment(msg.Body)
+			result, ok := results[messageID(d.PRNumber, i)]
+			if !ok {
+				missing++
+				continue
+			}
+			sentimentLabel, score := result.Sentiment, result.Score
 
 			if sentimentLabel == "" {
 				switch {
@@ -251,7 +211,11 @@ func analyzePRHealth(discussions []*analyzer.PRDiscussion) *HealthReport {
 				}
 			}
 
-			msgAnalysis := MessageAnalysis{
+			msgAnalysis := analyzer.MessageAnalysis{
+				PRNumber:  d.PRNumber,
+				Author:    msg.Author,
+				Kind:      msg.Kind,
+				CreatedAt: msg.CreatedAt,
 				Content:   msg.Body,
 				Sentiment: sentimentLabel,
 				Score:     score,
@@ -273,6 +237,7 @@ func analyzePRHealth(discussions []*analyzer.PRDiscussion) *HealthReport {
 		}
 	}
 
+	report.FailedCount = missing - report.InterruptedCount
 	report.MessageCount = len(report.Messages)
 	if report.MessageCount > 0 {
 		report.AverageSentiment = totalScore / float64(report.MessageCount)
@@ -281,6 +246,11 @@ func analyzePRHealth(discussions []*analyzer.PRDiscussion) *HealthReport {
 	return report
 }
 
+// messageID identifies a message within a health run for batch analysis.
+func messageID(prNumber, index int) string {
+	return fmt.Sprintf("%d-%d", prNumber, index)
+}
+
 func isBotComment(author string) bool {
 	botNames := []string{
 		// GitHub bots
@@ -313,70 +283,3 @@ func isBotComment(author string) bool {
 
 	return false
 }
-
-func displayHealthReport(report *HealthReport) {
-	if report.MessageCount == 0 {
-		fmt.Println("\n🔍 No messages found to analyze.")
-		return
-	}
-
-	fmt.Printf("\n📊 PR Health Report (%d PRs, %d messages analyzed)\n", report.PRCount, report.MessageCount)
-	fmt.Println(strings.Repeat("=", 50))
-	positivePct := 0.0
-	neutralPct := 0.0
-	negativePct := 0.0
-
-	if report.MessageCount > 0 {
-		total := float64(report.MessageCount)
-		positivePct = (float64(report.PositiveScore) / total) * 100
-		neutralPct = (float64(report.NeutralScore) / total) * 100
-		negativePct = (float64(report.NegativeScore) / total) * 100
-	}
-
-	fmt.Printf("\n🎭 Sentiment Analysis:")
-	fmt.Printf("\n✅ Positive: %.1f%%\n", positivePct)
-	fmt.Printf("😐 Neutral:  %.1f%%\n", neutralPct)
-	fmt.Printf("❌ Negative: %.1f%%\n", negativePct)
-	fmt.Printf("📈 Average Sentiment: %.1f/1.0\n", report.AverageSentiment)
-
-	fmt.Println("\n💬 Sample Messages:")
-	printed := 0
-	for _, msg := range report.Messages {
-		if printed >= 3 {
-			break
-		}
-		var emoji string
-		switch msg.Sentiment {
-		case "positive":
-			emoji = "✅"
-		case "negative":
-			emoji = "❌"
-		default:
-			emoji = "➖"
-		}
-		content := msg.Content
-		if len(content) > 100 {
-			content = content[:97] + "..."
-		}
-		fmt.Printf("%s [%.1f] %s\n", emoji, msg.Score, content)
-		printed++
-	}
-
-	fmt.Println("\n🏥 Health Assessment:")
-	switch {
-	case report.MessageCount == 0:
-		fmt.Println("ℹ️  No messages to analyze")
-	case report.NegativeScore/float64(report.MessageCount) > 0.5:
-		fmt.Println("⚠️  Needs attention - High level of negative sentiment")
-	case report.PositiveScore/float64(report.MessageCount) > 0.7:
-		fmt.Println("🌟 Excellent health - Very positive discussions")
-	case report.AverageSentiment > 0.6:
-		fmt.Println("👍 Good health - Generally positive discussions")
-	case report.NeutralScore/float64(report.MessageCount) > 0.7:
-		fmt.Println("➖ Neutral - Mostly technical discussions")
-	default:
-		fmt.Println("⚠️  Mixed sentiment - Review recommended")
-	}
-
-	fmt.Println(strings.Repeat("=", 50))
-}
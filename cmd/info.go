@@ -1,18 +1,17 @@
 package cmd
 
 import (
+	"errors"
 	"log"
 
 	"repo-doc/internal/analyzer"
-	"repo-doc/internal/output"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	format   string
-	prs      int
-	download string
+	format string
+	prs    int
 )
 
 var infoCmd = &cobra.Command{
@@ -24,17 +23,24 @@ var infoCmd = &cobra.Command{
 - Timestamps (created, last updated)
 - Recent pull requests (optional)
 
-The repository can be specified in two formats:
+The repository can be specified in several formats:
   1. Short format: owner/repo (e.g., golang/go)
-  2. Full URL: https://github.com/owner/repo
+  2. Full URL: https://github.com/owner/repo (a .git suffix or trailing path is fine)
+  3. SSH remote: git@github.com:owner/repo.git
+
+When no repository is given, the origin remote of the git checkout in the
+current directory is used (see --remote).
 
 Results can be displayed in multiple formats.`,
-	Args: cobra.ExactArgs(1),
+	Args: cobra.MaximumNArgs(1),
 	Run:  runAnalyze,
 	Example: `  # Basic repository info (table format, no PRs)
   repo-doc info golang/go
   repo-doc info https://github.com/microsoft/vscode
 
+  # Repository of the current git checkout
+  repo-doc info
+  repo-doc info --remote upstream
 
   # Show specific number of pull requests
   repo-doc info golang/go --prs 15
@@ -44,6 +50,10 @@ Results can be displayed in multiple formats.`,
   repo-doc info golang/go --format json
   repo-doc info golang/go -f json
 
+  # Write a JSON report to a file (format inferred from the extension)
+  repo-doc info golang/go --prs 20 -o report.json
+  repo-doc info golang/go --prs 20 -o report.json --force
+
   # Using authentication for higher rate limits
   repo-doc info golang/go --token ghp_xxxxxxxxxxxx --prs 50
   repo-doc info golang/go -t ghp_xxxxxxxxxxxx -p 30 -f json`,
@@ -51,23 +61,30 @@ Results can be displayed in multiple formats.`,
 
 func init() {
 	rootCmd.AddCommand(infoCmd)
+	addRemoteFlag(infoCmd)
+	addOutputFlags(infoCmd)
 
 	infoCmd.Flags().StringVarP(&format, "format", "f", "table",
 		`Output format for displaying results.
 Available options:
-  table - Human-readable table format with emojis (default)
-  json  - Machine-readable JSON format
+  table    - Human-readable table format with emojis (default)
+  json     - Machine-readable JSON format
+  markdown - GitHub-flavoured markdown for issues and wikis
+  csv, tsv - One row per pull request, for spreadsheets
+  html     - Self-contained dashboard page
+  template - Your own Go text/template (see --template)
 
 Examples:
   --format table  (default, shows nicely formatted table)
   --format json   (shows structured JSON data)
+  --format markdown
   -f table
   -f json`)
 
 	infoCmd.Flags().IntVarP(&prs, "prs", "p", 0,
 		`Number of recent pull requests to display.
 Behavior:
-  - --prs N: Shows N recent pull requests (max 100)
+  - --prs N: Shows N recent pull requests (fetched across pages as needed)
 
 Examples:
   --prs 5    (shows 5 recent PRs)
@@ -76,39 +93,41 @@ Examples:
 }
 
 func runAnalyze(cmd *cobra.Command, args []string) {
-	repoURL := args[0]
-
-	owner, repo, err := analyzer.ParseRepoURL(repoURL)
-	if err != nil {
-		log.Fatalf("Error parsing repository URL: %v", err)
-	}
+	ref := resolveRepo(args)
+	owner, repo := ref.Owner, ref.Name
+	outputManager := newOutputManager(cmd)
 
 	prLimit := determinePRLimit(cmd)
 
-	if prLimit > 100 {
-		log.Fatalf("PR limit must be 100 or less")
-	}
-
-	a := analyzer.New(token)
+	ctx := cmd.Context()
+	a := newAnalyzer()
 
-	repoInfo, err := a.FetchRepoInfo(owner, repo)
+	repoInfo, err := a.FetchRepoInfo(ctx, owner, repo)
 	if err != nil {
 		log.Fatalf("Error fetching repository info: %v", err)
 	}
 
 	var prInfos []*analyzer.PRInfo
 	if prLimit > 0 {
-		prInfos, err = a.FetchPullRequests(owner, repo, prLimit)
-		if err != nil {
+		prInfos, err = a.FetchPullRequests(ctx, owner, repo, prLimit)
+		var partial *analyzer.PartialError
+		switch {
+		case err == nil:
+		case strict:
+			log.Fatalf("Error fetching pull requests: %v", err)
+		case errors.As(err, &partial):
+			log.Printf("Showing the %d pull requests fetched before the error: %v", len(prInfos), err)
+		case ctx.Err() != nil:
+			log.Printf("Interrupted while fetching pull requests: %v", err)
+		default:
 			log.Fatalf("Error fetching pull requests: %v", err)
 		}
 	}
 
-	outputManager := output.New(format, download)
-
 	if err := outputManager.Display(repoInfo, prInfos); err != nil {
 		log.Fatalf("Error displaying output: %v", err)
 	}
+	reportWritten(outputManager)
 }
 
 func determinePRLimit(cmd *cobra.Command) int {
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"repo-doc/internal/analyzer"
+
+	"github.com/spf13/cobra"
+)
+
+var prCmd = &cobra.Command{
+	Use:   "pr [owner/repo] <number | PR URL>",
+	Short: "Show everything about a single pull request",
+	Long: `Show one pull request in full:
+- Description, labels, assignees and requested reviewers
+- Review states (approved, changes requested)
+- CI check results for the head commit
+- Files changed with additions and deletions
+- The whole discussion thread
+- A sentiment breakdown of the discussion
+
+The PR can be given as a number (the repository is then detected from the
+git checkout in the current directory, see --remote), as owner/repo and a
+number, or as a pull request URL.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runPR,
+	Example: `  # PR in the repository of the current directory
+  repo-doc pr 74251
+
+  # PR in another repository
+  repo-doc pr golang/go 74251
+  repo-doc pr https://github.com/golang/go/pull/74251
+
+  # Offline sentiment breakdown
+  repo-doc pr golang/go 74251 --sentiment-provider lexicon`,
+}
+
+func init() {
+	rootCmd.AddCommand(prCmd)
+	addRemoteFlag(prCmd)
+	addSentimentFlags(prCmd)
+	addFormatFlag(prCmd)
+	addOutputFlags(prCmd)
+}
+
+func runPR(cmd *cobra.Command, args []string) {
+	ref := resolvePRRef(args)
+	if ref.Number == 0 {
+		log.Fatalf("No pull request number given for %s", ref)
+	}
+
+	ctx := cmd.Context()
+	outputManager := newOutputManager(cmd)
+
+	backend := newSentimentBackend(ctx)
+	defer backend.Close()
+
+	a := newAnalyzer()
+
+	detail, err := a.FetchPRDetail(ctx, ref.Owner, ref.Name, ref.Number)
+	if detail == nil {
+		log.Fatalf("Error fetching pull request: %v", err)
+	}
+	checkDiscussionsErr(err)
+	addRepoContext(ctx, a, outputManager, ref)
+
+	report := analyzePRHealth(ctx, backend, []*analyzer.PRDiscussion{detail.Discussion})
+	if err := outputManager.DisplayPR(detail, report); err != nil {
+		log.Fatalf("Error displaying output: %v", err)
+	}
+	reportWritten(outputManager)
+}
+
+// resolvePRRef interprets the pr command's arguments: a PR URL, a bare
+// number in the detected repository, or a repository followed by a number.
+func resolvePRRef(args []string) *analyzer.RepoRef {
+	last := strings.TrimPrefix(args[len(args)-1], "#")
+	number, err := strconv.Atoi(last)
+	if err != nil {
+		if len(args) == 2 {
+			log.Fatalf("Invalid pull request number: %s", args[1])
+		}
+		return parseRepoArg(args[0])
+	}
+	if number < 1 {
+		log.Fatalf("Invalid pull request number: %d", number)
+	}
+
+	ref := resolveRepo(args[:len(args)-1])
+	ref.Number = number
+	return ref
+}
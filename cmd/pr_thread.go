@@ -3,7 +3,7 @@ package cmd
 import (
 	"fmt"
 	"log"
-	"strings"
+	"os"
 
 	"repo-doc/internal/analyzer"
 
@@ -15,88 +15,83 @@ var (
 )
 
 var prThreadCmd = &cobra.Command{
-	Use:   "pr-thread [owner/repo or URL]",
+	Use:   "pr-thread [owner/repo, repo URL or PR URL]",
 	Short: "Display discussion threads from pull requests",
 	Long: `Fetch and display discussion threads from the most recent pull requests in a repository.
 
 This command shows the conversation history including:
 - PR description (first message)
 - General comments on the PR
-- Review comments on the code
+- Review summaries with their verdict (approved, changes requested)
+- Review comments on the code, grouped by file and line with the diff
+  hunk and nested replies
 
-Results are shown in chronological order for each PR.`,
-	Args: cobra.ExactArgs(1),
+Comments are shown in chronological order for each PR, followed by the
+review conversations. --since and --until restrict the messages shown to a
+time window; PRs without messages in it are skipped.
+
+Pass a pull request URL to show only that PR's thread. Without an argument,
+the repository of the git checkout in the current directory is used.`,
+	Args: cobra.MaximumNArgs(1),
 	Run:  runPRDiscussions,
 	Example: `  # Show threads from the 5 most recent PRs
   repo-doc pr-thread golang/go
 
+  # Show threads for the repository in the current directory
+  repo-doc pr-thread
+
+  # Only messages from the last week
+  repo-doc pr-thread golang/go --since 7d
+
   # Show threads from 3 most recent PRs
   repo-doc pr-thread golang/go --limit 3
 
   # Show threads using full GitHub URL
   repo-doc pr-thread https://github.com/golang/go
 
+  # Show a single PR's thread
+  repo-doc pr-thread https://github.com/golang/go/pull/74251
+
+  # Save the threads as JSON
+  repo-doc pr-thread golang/go -o threads.json
+
   # Using authentication for private repositories
   repo-doc pr-thread myorg/private-repo --token ghp_xxxxxxxxxxxx`,
 }
 
 func init() {
 	rootCmd.AddCommand(prThreadCmd)
+	addRemoteFlag(prThreadCmd)
+	addTimeRangeFlags(prThreadCmd)
+	addFormatFlag(prThreadCmd)
+	addOutputFlags(prThreadCmd)
 
 	prThreadCmd.Flags().IntVarP(&discussionsLimit, "limit", "l", 5,
-		`Number of most recent PRs to fetch threads from (max 20).
+		`Number of most recent PRs to fetch threads from.
 Use a higher limit with caution as it may hit rate limits.`)
 }
 
 func runPRDiscussions(cmd *cobra.Command, args []string) {
-	repoURL := args[0]
-
-	owner, repo, err := analyzer.ParseRepoURL(repoURL)
-	if err != nil {
-		log.Fatalf("Error parsing repository URL: %v", err)
-	}
+	ref := resolveRepo(args)
+	start, end := timeRange()
+	outputManager := newOutputManager(cmd)
 
-	if discussionsLimit < 1 || discussionsLimit > 20 {
+	if discussionsLimit < 1 {
 		discussionsLimit = 5
 	}
 
-	a := analyzer.New(token)
+	a := newAnalyzer()
 
-	discussions, err := a.FetchPRDiscussions(owner, repo, discussionsLimit)
-	if err != nil {
-		log.Fatalf("Error fetching PR discussions: %v", err)
+	discussions := fetchDiscussions(cmd.Context(), a, ref, discussionsLimit)
+	discussions = analyzer.FilterByTime(discussions, start, end)
+	if len(discussions) == 0 && (!start.IsZero() || !end.IsZero()) {
+		fmt.Fprintln(os.Stderr, "🔍 No messages found in the given time range.")
+		return
 	}
 
-	for _, discussion := range discussions {
-		statusEmoji := "🟢" // Open PR
-		if discussion.Merged {
-			statusEmoji = "🟣" // Merged PR
-		} else if strings.EqualFold(discussion.State, "closed") {
-			statusEmoji = "🔴" // Closed PR
-		}
-
-		header := fmt.Sprintf("%s #%d: %s (👤 %s)", statusEmoji, discussion.PRNumber, discussion.Title, discussion.Author)
-		fmt.Println("\n" + strings.Repeat("=", len(header)))
-		fmt.Println(header)
-		fmt.Println(strings.Repeat("=", len(header)))
-
-		for i, msg := range discussion.Messages {
-			if i > 0 {
-				fmt.Println("\n" + strings.Repeat("─", 60))
-			}
-			authorEmoji := "💬"
-			if msg.IsPRBody {
-				authorEmoji = "📝"
-			}
-
-			header := fmt.Sprintf("%s %s (%s)", authorEmoji, msg.Author, msg.CreatedAt)
-			if msg.IsPRBody {
-				header = "📌 " + header
-			}
-
-			fmt.Printf("\n%s\n%s\n", header, strings.Repeat("-", len(header)))
-			fmt.Println(msg.Body)
-		}
-		fmt.Println("\n" + strings.Repeat("=", 50))
+	addRepoContext(cmd.Context(), a, outputManager, ref)
+	if err := outputManager.DisplayDiscussions(discussions); err != nil {
+		log.Fatalf("Error displaying output: %v", err)
 	}
+	reportWritten(outputManager)
 }
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var rateLimitCmd = &cobra.Command{
+	Use:   "rate-limit",
+	Short: "Show the remaining GitHub API quota",
+	Long: `Show the remaining GitHub API quota for the current credentials.
+
+Checking the rate limit does not count against it.`,
+	Args: cobra.NoArgs,
+	Run:  runRateLimit,
+	Example: `  repo-doc rate-limit
+  repo-doc rate-limit --token ghp_xxxxxxxxxxxx`,
+}
+
+func init() {
+	rootCmd.AddCommand(rateLimitCmd)
+}
+
+func runRateLimit(cmd *cobra.Command, args []string) {
+	a := newAnalyzer()
+
+	limits, err := a.FetchRateLimits(cmd.Context())
+	if err != nil {
+		log.Fatalf("Error fetching rate limits: %v", err)
+	}
+
+	fmt.Println("📡 GitHub API Rate Limits")
+	fmt.Println(strings.Repeat("=", 50))
+	for _, l := range limits {
+		emoji := "🟢"
+		switch {
+		case l.Remaining == 0:
+			emoji = "🔴"
+		case l.Limit > 0 && float64(l.Remaining)/float64(l.Limit) < 0.1:
+			emoji = "🟡"
+		}
+
+		resetIn := time.Until(l.Reset).Round(time.Second)
+		if resetIn < 0 {
+			resetIn = 0
+		}
+		fmt.Printf("%s %-8s %5d / %-5d resets in %s (%s)\n",
+			emoji, l.Resource, l.Remaining, l.Limit, resetIn, l.Reset.Local().Format("15:04:05"))
+	}
+	fmt.Println(strings.Repeat("=", 50))
+}
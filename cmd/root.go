@@ -1,8 +1,23 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"net/url"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"repo-doc/internal/analyzer"
+	"repo-doc/internal/cache"
+	"repo-doc/internal/gitconfig"
+	"repo-doc/internal/output"
+	"repo-doc/internal/retry"
 
 	"github.com/spf13/cobra"
 )
@@ -27,7 +42,12 @@ Analyze repos, surface rich metadata, and check PR health with AI. Get stats, th
 
 Authentication:
   Use --token or set GITHUB_TOKEN for higher rate limits.
-  Get your token at: https://github.com/settings/tokens`,
+  Get your token at: https://github.com/settings/tokens
+  Automation can authenticate as a GitHub App with --app-id,
+  --app-installation-id and --app-private-key instead.
+
+GitHub Enterprise Server:
+  Use --github-url or set GITHUB_API_URL to your instance's URL.`,
 
 	Example: `  # Repository information
   repo-doc info golang/go
@@ -36,13 +56,39 @@ Authentication:
   # PR analysis
   repo-doc pr-thread golang/go --limit 3
   repo-doc health golang/go --limit 5`,
+
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if timeout > 0 {
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			cancelTimeout = cancel
+			cmd.SetContext(ctx)
+		}
+	},
+
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		printUsageFooter()
+	},
 }
 
+// Execute runs the root command. The first SIGINT/SIGTERM cancels
+// in-flight requests so commands can print what they gathered so far; a
+// second one terminates immediately.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	context.AfterFunc(ctx, stop)
+
+	err := rootCmd.ExecuteContext(ctx)
+	cancelTimeout()
+	interrupted := ctx.Err() != nil
+	stop()
+
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+	if interrupted {
+		os.Exit(130)
+	}
 }
 
 func init() {
@@ -52,6 +98,393 @@ Can also be set via GITHUB_TOKEN environment variable.
 Without a token, you're limited to 60 requests per hour.
 With a token, you get 5000 requests per hour.
 Get your token at: https://github.com/settings/tokens`)
+
+	rootCmd.PersistentFlags().IntVar(&concurrency, "concurrency", analyzer.DefaultConcurrency,
+		`Number of pull requests fetched in parallel.
+All requests share one rate limiter that stays under GitHub's
+secondary rate limits, so raising this mainly helps with slow responses.`)
+
+	rootCmd.PersistentFlags().BoolVar(&strict, "strict", false,
+		`Fail instead of showing partial results when some GitHub API calls fail.`)
+
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0,
+		`Overall time limit for the command, e.g. 30s or 2m (0 means no limit).
+When it expires, results gathered so far are shown.`)
+
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false,
+		`Bypass the on-disk HTTP cache ($XDG_CACHE_HOME/repo-doc).
+Cached responses are revalidated with ETags, so unchanged data
+does not count against the GitHub rate limit.`)
+
+	rootCmd.PersistentFlags().StringVar(&githubURL, "github-url", "",
+		`Base URL of a GitHub Enterprise Server instance,
+e.g. https://github.example.com (the /api/v3 suffix is optional).
+Can also be set via GITHUB_API_URL environment variable.
+Defaults to https://api.github.com.`)
+
+	rootCmd.PersistentFlags().DurationVar(&maxWait, "max-wait", analyzer.DefaultMaxWait,
+		`Longest time to wait for an exhausted GitHub rate limit to reset
+before failing, e.g. 0 (fail immediately), 90s or 1h.
+Retry-After is honoured for secondary rate limits.`)
+
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", retry.DefaultRetries,
+		`How many times to retry GitHub and LLM requests that fail with
+a 5xx error, a reset connection or a timeout (0 disables retries).
+Retries use jittered exponential backoff.`)
+
+	rootCmd.PersistentFlags().Int64Var(&appID, "app-id", 0,
+		`Authenticate as a GitHub App with this app ID instead of a token.
+Requires --app-installation-id and --app-private-key.
+Can also be set via GITHUB_APP_ID environment variable.`)
+	rootCmd.PersistentFlags().Int64Var(&appInstallationID, "app-installation-id", 0,
+		`GitHub App installation ID to request access tokens for.
+Can also be set via GITHUB_APP_INSTALLATION_ID environment variable.`)
+	rootCmd.PersistentFlags().StringVar(&appPrivateKey, "app-private-key", "",
+		`Path to the GitHub App private key (.pem).
+Can also be set via GITHUB_APP_PRIVATE_KEY_PATH environment variable.`)
+}
+
+var (
+	token       string
+	concurrency int
+	strict      bool
+	timeout     time.Duration
+	noCache     bool
+	githubURL   string
+	maxWait     time.Duration
+	retries     int
+
+	cancelTimeout = func() {}
+
+	// activeAnalyzer is the Analyzer created for the running command, kept
+	// for the API usage footer.
+	activeAnalyzer *analyzer.Analyzer
+
+	appID             int64
+	appInstallationID int64
+	appPrivateKey     string
+
+	gitRemote string
+
+	since string
+	until string
+
+	outputPath   string
+	force        bool
+	templatePath string
+)
+
+// resolveRepo returns the repository named in args, or detects it from the
+// --remote of the git checkout in the working directory when args is empty.
+func resolveRepo(args []string) *analyzer.RepoRef {
+	if len(args) > 0 {
+		return parseRepoArg(args[0])
+	}
+
+	url, err := gitconfig.RemoteURL(".", gitRemote)
+	if err != nil {
+		log.Fatalf("No repository given and none detected: %v\nPass owner/repo or run inside a git checkout", err)
+	}
+	log.Printf("Using repository from git remote %q: %s", gitRemote, url)
+	return parseRepoArg(url)
+}
+
+// addRemoteFlag registers --remote on commands that take an optional
+// repository argument.
+func addRemoteFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&gitRemote, "remote", "origin",
+		`Git remote used to detect the repository when none is given.`)
 }
 
-var token string
+// addOutputFlags registers --output and --force on commands that produce a
+// report.
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&outputPath, "output", "o", "",
+		`Write the report to this file instead of stdout.
+The format is inferred from the extension (.json, .md, .csv, .tsv,
+.html, .txt) unless
+--format is given. Existing files are kept unless --force is set.`)
+	cmd.Flags().BoolVar(&force, "force", false,
+		`Overwrite the --output file if it already exists.`)
+	cmd.Flags().StringVar(&templatePath, "template", "",
+		`Go text/template file used by --format template (implied when set).
+Templates see .Repository, .PullRequests, .Discussions, .Health and
+.PullRequest, and can use truncate, date, percent, stateEmoji and join.`)
+}
+
+// addFormatFlag registers --format on commands that render discussions.
+func addFormatFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&format, "format", "f", "table",
+		`Output format: table (default), json, markdown, csv, tsv, html or
+template (see --template).
+csv and tsv write one row per message (pr-thread, pr) or per
+analyzed message (health); html is a self-contained dashboard.`)
+}
+
+// newOutputManager builds an output.Manager from --format, --output and
+// --force, failing before any API calls if the output file is unusable.
+func newOutputManager(cmd *cobra.Command) *output.Manager {
+	f := format
+	if !cmd.Flags().Changed("format") {
+		switch {
+		case templatePath != "":
+			f = "template"
+		case outputPath != "":
+			f = ""
+		}
+	}
+
+	m := output.New(f, outputPath, force)
+	if err := m.CheckDestination(); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if m.Format() == "template" {
+		if templatePath == "" {
+			log.Fatalf("Error: --format template needs a template file (--template)")
+		}
+		if err := m.LoadTemplate(templatePath); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+	return m
+}
+
+// addRepoContext fetches repository details for formats that show them
+// alongside PR reports.
+func addRepoContext(ctx context.Context, a *analyzer.Analyzer, m *output.Manager, ref *analyzer.RepoRef) {
+	if f := m.Format(); f != "html" && f != "template" {
+		return
+	}
+
+	info, err := a.FetchRepoInfo(ctx, ref.Owner, ref.Name)
+	if err != nil {
+		log.Printf("Warning: repository details unavailable: %v", err)
+		return
+	}
+	m.SetRepository(info)
+}
+
+// reportWritten tells the user where the report went when it was written
+// to a file.
+func reportWritten(m *output.Manager) {
+	if m.Path() != "" {
+		fmt.Fprintf(os.Stderr, "📄 Report written to %s\n", m.Path())
+	}
+}
+
+// addTimeRangeFlags registers --since and --until on commands that show
+// discussions.
+func addTimeRangeFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&since, "since", "",
+		`Only include messages posted at or after this time.
+Accepts a date (2024-01-31), an RFC 3339 time (2024-01-31T15:04:05Z)
+or a duration before now (36h, 7d).`)
+	cmd.Flags().StringVar(&until, "until", "",
+		`Only include messages posted before this time.
+Same formats as --since; a date includes that whole day.`)
+}
+
+// timeRange returns the parsed --since and --until bounds. Unset bounds
+// are zero.
+func timeRange() (time.Time, time.Time) {
+	start, err := parseTimeFlag(since, false)
+	if err != nil {
+		log.Fatalf("Invalid --since: %v", err)
+	}
+	end, err := parseTimeFlag(until, true)
+	if err != nil {
+		log.Fatalf("Invalid --until: %v", err)
+	}
+	if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+		log.Fatalf("--since must be before --until")
+	}
+	return start, end
+}
+
+// parseTimeFlag parses a date, an RFC 3339 time or a duration before now.
+// With endOfDay, a bare date means the end of that day.
+func parseTimeFlag(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+
+	// time.ParseDuration has no unit for days.
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return time.Now().AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return time.Now().Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("%q is not a date, RFC 3339 time or duration", value)
+}
+
+// parseRepoArg parses a repository argument or exits. A URL on another host
+// selects that GitHub Enterprise Server unless --github-url or
+// GITHUB_API_URL is set.
+func parseRepoArg(arg string) *analyzer.RepoRef {
+	ref, err := analyzer.ParseRepoURL(arg)
+	if err != nil {
+		log.Fatalf("Error parsing repository URL: %v", err)
+	}
+
+	if ref.Host != "github.com" && githubURL == "" && os.Getenv("GITHUB_API_URL") == "" {
+		githubURL = "https://" + ref.Host
+	}
+	return ref
+}
+
+// fetchDiscussions returns the thread of the PR named in ref, or of the
+// limit most recent PRs when ref has no number.
+func fetchDiscussions(ctx context.Context, a *analyzer.Analyzer, ref *analyzer.RepoRef, limit int) []*analyzer.PRDiscussion {
+	if ref.Number == 0 {
+		discussions, err := a.FetchPRDiscussions(ctx, ref.Owner, ref.Name, limit)
+		checkDiscussionsErr(err)
+		return discussions
+	}
+
+	discussion, err := a.FetchPRDiscussion(ctx, ref.Owner, ref.Name, ref.Number)
+	checkDiscussionsErr(err)
+	return []*analyzer.PRDiscussion{discussion}
+}
+
+// checkDiscussionsErr handles the error returned by FetchPRDiscussions.
+// Partial failures are reported on stderr and tolerated unless --strict
+// is set; any other error is fatal.
+func checkDiscussionsErr(err error) {
+	if err == nil {
+		return
+	}
+
+	var partial *analyzer.PartialError
+	if !errors.As(err, &partial) {
+		log.Fatalf("Error fetching PR discussions: %v", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "⚠️  Warning: %v\n", partial)
+	for _, f := range partial.Failures {
+		for _, e := range f.Errors {
+			fmt.Fprintf(os.Stderr, "   #%d: %v\n", f.PRNumber, e)
+		}
+	}
+	if errors.Is(err, analyzer.ErrForbidden) {
+		fmt.Fprintln(os.Stderr, "   Check that your GitHub token is valid and has access to this repository.")
+	}
+
+	if strict {
+		fmt.Fprintln(os.Stderr, "Aborting because --strict is set")
+		os.Exit(1)
+	}
+}
+
+// appCredentials returns the GitHub App settings from flags or environment.
+// App authentication is used when an app ID is configured.
+func appCredentials() (analyzer.AppCredentials, bool) {
+	creds := analyzer.AppCredentials{
+		AppID:          appID,
+		InstallationID: appInstallationID,
+		PrivateKeyPath: appPrivateKey,
+	}
+
+	if creds.AppID == 0 {
+		creds.AppID = envInt64("GITHUB_APP_ID")
+	}
+	if creds.InstallationID == 0 {
+		creds.InstallationID = envInt64("GITHUB_APP_INSTALLATION_ID")
+	}
+	if creds.PrivateKeyPath == "" {
+		creds.PrivateKeyPath = os.Getenv("GITHUB_APP_PRIVATE_KEY_PATH")
+	}
+
+	return creds, creds.AppID != 0
+}
+
+func envInt64(name string) int64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", name, err)
+	}
+	return n
+}
+
+// isGitHubDotCom reports whether rawURL points at github.com rather than an
+// Enterprise Server instance, whatever its path or trailing slash.
+func isGitHubDotCom(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == "github.com" || host == "api.github.com"
+}
+
+// newAnalyzer builds an Analyzer from the global flags.
+func newAnalyzer() *analyzer.Analyzer {
+	opts := []analyzer.Option{
+		analyzer.WithConcurrency(concurrency),
+		analyzer.WithMaxWait(maxWait),
+		analyzer.WithRetries(retries),
+	}
+
+	if !noCache {
+		store, err := cache.Open("http")
+		if err != nil {
+			log.Printf("Warning: HTTP cache disabled: %v", err)
+		} else {
+			opts = append(opts, analyzer.WithCache(store))
+		}
+	}
+
+	baseURL := githubURL
+	if baseURL == "" {
+		baseURL = os.Getenv("GITHUB_API_URL")
+	}
+	if baseURL != "" && !isGitHubDotCom(baseURL) {
+		opts = append(opts, analyzer.WithBaseURL(baseURL))
+	}
+
+	if creds, ok := appCredentials(); ok {
+		opts = append(opts, analyzer.WithAppAuth(creds))
+	}
+
+	a, err := analyzer.New(token, opts...)
+	if err != nil {
+		log.Fatalf("Error creating GitHub client: %v", err)
+	}
+	activeAnalyzer = a
+	return a
+}
+
+// printUsageFooter reports on stderr how many GitHub API calls the command
+// made, keeping stdout clean for reports.
+func printUsageFooter() {
+	if activeAnalyzer == nil {
+		return
+	}
+
+	usage := activeAnalyzer.Usage()
+	footer := fmt.Sprintf("📡 %d GitHub API calls", usage.Calls)
+	if usage.NotModified > 0 {
+		footer += fmt.Sprintf(" (%d unchanged, served from cache)", usage.NotModified)
+	}
+	if usage.Remaining >= 0 {
+		footer += fmt.Sprintf(", %d remaining", usage.Remaining)
+	}
+	fmt.Fprintln(os.Stderr, footer)
+}
@@ -2,12 +2,21 @@ package analyzer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"sort"
 	"strings"
+	"sync"
+	"time"
+
+	"repo-doc/internal/cache"
+	"repo-doc/internal/retry"
 
 	"github.com/google/go-github/v56/github"
 	"golang.org/x/oauth2"
+	"golang.org/x/time/rate"
 )
 
 type RepoInfo struct {
@@ -40,48 +49,166 @@ type PRDiscussion struct {
 }
 
 type DiscussionMessage struct {
+	Kind      MessageKind
 	Author    string
 	Body      string
-	CreatedAt string
-	IsPRBody  bool
+	CreatedAt time.Time
+
+	// Set for reviews only: APPROVED, CHANGES_REQUESTED, COMMENTED or
+	// DISMISSED.
+	ReviewState string
+
+	// Set for review comments only.
+	ID        int64
+	Path      string
+	Line      int
+	DiffHunk  string
+	InReplyTo int64
 }
 
+// MessageKind tells where in a PR a DiscussionMessage was posted.
+type MessageKind string
+
+const (
+	// KindDescription is the PR body.
+	KindDescription MessageKind = "description"
+	// KindComment is a comment on the PR conversation tab.
+	KindComment MessageKind = "comment"
+	// KindReview is the summary submitted with a review.
+	KindReview MessageKind = "review"
+	// KindReviewComment is a comment on a line of the diff.
+	KindReviewComment MessageKind = "review_comment"
+)
+
 type Analyzer struct {
-	client *github.Client
+	client      *github.Client
+	concurrency int
+	limiter     *rate.Limiter
+	cache       *cache.Store
+	baseURL     string
+	app         *AppCredentials
+	maxWait     time.Duration
+	retries     int
+	usage       *rateLimitTransport
 }
 
-func New(token string) *Analyzer {
-	client := createGitHubClient(token)
-	return &Analyzer{client: client}
-}
+// DefaultConcurrency is the number of PRs fetched in parallel.
+const DefaultConcurrency = 4
+
+// Option configures an Analyzer.
+type Option func(*Analyzer)
 
-func ParseRepoURL(url string) (string, string, error) {
-	if !strings.Contains(url, "github.com") && strings.Contains(url, "/") {
-		parts := strings.Split(url, "/")
-		if len(parts) == 2 {
-			return parts[0], parts[1], nil
+// WithConcurrency sets how many PRs are fetched in parallel.
+func WithConcurrency(n int) Option {
+	return func(a *Analyzer) {
+		if n > 0 {
+			a.concurrency = n
 		}
 	}
+}
+
+// WithCache stores GitHub responses in store and revalidates them with
+// conditional requests.
+func WithCache(store *cache.Store) Option {
+	return func(a *Analyzer) {
+		a.cache = store
+	}
+}
 
-	url = strings.TrimPrefix(url, "https://")
-	url = strings.TrimPrefix(url, "http://")
-	url = strings.TrimPrefix(url, "github.com/")
+// WithBaseURL points the client at a GitHub Enterprise Server instance,
+// e.g. https://github.example.com or https://github.example.com/api/v3.
+// An empty URL keeps api.github.com.
+func WithBaseURL(baseURL string) Option {
+	return func(a *Analyzer) {
+		a.baseURL = baseURL
+	}
+}
 
-	parts := strings.Split(url, "/")
-	if len(parts) < 2 {
-		return "", "", fmt.Errorf("invalid repository format. Use 'owner/repo' or full GitHub URL")
+// WithMaxWait sets how long a request may wait for an exhausted rate limit
+// to reset. Zero fails immediately.
+func WithMaxWait(d time.Duration) Option {
+	return func(a *Analyzer) {
+		a.maxWait = d
 	}
+}
 
-	return parts[0], parts[1], nil
+// WithRetries sets how many times transient GitHub failures are retried.
+func WithRetries(n int) Option {
+	return func(a *Analyzer) {
+		if n >= 0 {
+			a.retries = n
+		}
+	}
 }
 
-func (a *Analyzer) FetchRepoInfo(owner, repo string) (*RepoInfo, error) {
-	ctx := context.Background()
+func New(token string, opts ...Option) (*Analyzer, error) {
+	a := &Analyzer{
+		concurrency: DefaultConcurrency,
+		limiter:     rate.NewLimiter(defaultRequestsPerSecond, defaultBurst),
+		maxWait:     DefaultMaxWait,
+		retries:     retry.DefaultRetries,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
 
-	repository, _, err := a.client.Repositories.Get(ctx, owner, repo)
+	a.usage = &rateLimitTransport{
+		base:    &limitedTransport{base: http.DefaultTransport, limiter: a.limiter},
+		maxWait: a.maxWait,
+	}
+	a.usage.remaining.Store(-1)
+
+	var transport http.RoundTripper = &retryTransport{base: a.usage, retries: a.retries}
+	if a.cache != nil {
+		ct := &cache.Transport{Base: transport, Store: a.cache}
+		if a.app != nil {
+			// Installation tokens rotate, so key entries on the
+			// installation for them to be reused by later runs.
+			ct.Credential = fmt.Sprintf("app %d installation %d", a.app.AppID, a.app.InstallationID)
+		}
+		transport = ct
+	}
+
+	if a.app != nil {
+		src, err := newAppTokenSource(*a.app, a.baseURL, transport)
+		if err != nil {
+			return nil, err
+		}
+		client, err := withBaseURL(github.NewClient(&http.Client{Transport: &appTransport{base: transport, src: src}}), a.baseURL)
+		if err != nil {
+			return nil, err
+		}
+		a.client = client
+		return a, nil
+	}
+
+	// Check token from parameter first, then environment
+	githubToken := token
+	if githubToken == "" {
+		githubToken = os.Getenv("GITHUB_TOKEN")
+	}
+	var ts oauth2.TokenSource
+	if githubToken != "" {
+		ts = oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: githubToken},
+		)
+	}
+
+	client, err := createGitHubClient(ts, a.baseURL, transport)
 	if err != nil {
 		return nil, err
 	}
+	a.client = client
+	return a, nil
+}
+
+func (a *Analyzer) FetchRepoInfo(ctx context.Context, owner, repo string) (*RepoInfo, error) {
+	repository, _, err := awaitRateLimit(ctx, a.maxWait, func() (*github.Repository, *github.Response, error) {
+		return a.client.Repositories.Get(ctx, owner, repo)
+	})
+	if err != nil {
+		return nil, classify("get repository", err)
+	}
 
 	info := &RepoInfo{
 		Name:        safeString(repository.Name),
@@ -103,141 +230,379 @@ func (a *Analyzer) FetchRepoInfo(owner, repo string) (*RepoInfo, error) {
 	return info, nil
 }
 
-func (a *Analyzer) IsMerged(owner, repo string, prNumber int) (bool, error) {
-	ctx := context.Background()
-	isMerged, _, err := a.client.PullRequests.IsMerged(ctx, owner, repo, prNumber)
+func (a *Analyzer) IsMerged(ctx context.Context, owner, repo string, prNumber int) (bool, error) {
+	isMerged, _, err := awaitRateLimit(ctx, a.maxWait, func() (bool, *github.Response, error) {
+		return a.client.PullRequests.IsMerged(ctx, owner, repo, prNumber)
+	})
 	if err != nil {
-		return false, nil // Assume not merged if there's an error
+		return false, classify(fmt.Sprintf("check merge status of #%d", prNumber), err)
 	}
 	return isMerged, nil
 }
 
-func (a *Analyzer) FetchPullRequests(owner, repo string, limit int) ([]*PRInfo, error) {
-	ctx := context.Background()
+// FetchPullRequests returns the limit most recent PRs. When a later page
+// fails or ctx is cancelled, the PRs already fetched are returned together
+// with a *PartialError wrapping the cause.
+func (a *Analyzer) FetchPullRequests(ctx context.Context, owner, repo string, limit int) ([]*PRInfo, error) {
+	perPage := limit
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
 
 	opts := &github.PullRequestListOptions{
 		State: "all",
 		ListOptions: github.ListOptions{
-			PerPage: limit,
+			PerPage: perPage,
 		},
 	}
 
-	prs, _, err := a.client.PullRequests.List(ctx, owner, repo, opts)
-	if err != nil {
-		return nil, err
-	}
-
 	var prInfos []*PRInfo
-	for _, pr := range prs {
-		if len(prInfos) >= limit {
-			break
+	for {
+		prs, resp, err := awaitRateLimit(ctx, a.maxWait, func() ([]*github.PullRequest, *github.Response, error) {
+			return a.client.PullRequests.List(ctx, owner, repo, opts)
+		})
+		if err != nil {
+			err = classify("list pull requests", err)
+			if len(prInfos) > 0 {
+				return prInfos, &PartialError{Total: limit, Cause: err}
+			}
+			return nil, err
 		}
 
-		var author string
-		if pr.User != nil && pr.User.Login != nil {
-			author = *pr.User.Login
+		for _, pr := range prs {
+			if len(prInfos) >= limit {
+				break
+			}
+			prInfos = append(prInfos, newPRInfo(pr))
 		}
 
-		isMerged := pr.GetState() == "closed" && !pr.GetMergedAt().IsZero()
-
-		prInfos = append(prInfos, &PRInfo{
-			Number: pr.GetNumber(),
-			Title:  pr.GetTitle(),
-			State:  pr.GetState(),
-			Author: author,
-			Merged: isMerged,
-		})
+		if len(prInfos) >= limit || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
 	}
 
 	return prInfos, nil
 }
 
-func (a *Analyzer) FetchPRDiscussions(owner, repo string, limit int) ([]*PRDiscussion, error) {
-	prs, err := a.FetchPullRequests(owner, repo, limit)
+func newPRInfo(pr *github.PullRequest) *PRInfo {
+	var author string
+	if pr.User != nil && pr.User.Login != nil {
+		author = *pr.User.Login
+	}
+
+	isMerged := pr.GetState() == "closed" && !pr.GetMergedAt().IsZero()
+
+	return &PRInfo{
+		Number: pr.GetNumber(),
+		Title:  pr.GetTitle(),
+		State:  pr.GetState(),
+		Author: author,
+		Merged: isMerged,
+	}
+}
+
+// FetchPRDiscussion returns the thread of a single PR. Like
+// FetchPRDiscussions, a *PartialError accompanies a partially fetched thread.
+func (a *Analyzer) FetchPRDiscussion(ctx context.Context, owner, repo string, number int) (*PRDiscussion, error) {
+	pr, _, err := awaitRateLimit(ctx, a.maxWait, func() (*github.PullRequest, *github.Response, error) {
+		return a.client.PullRequests.Get(ctx, owner, repo, number)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error fetching pull requests: %v", err)
+		return nil, classify(fmt.Sprintf("get pull request #%d", number), err)
+	}
+
+	discussion, errs := a.fetchDiscussion(ctx, owner, repo, newPRInfo(pr), pr)
+	if len(errs) > 0 {
+		return discussion, &PartialError{Total: 1, Failures: []PRFailure{{PRNumber: number, Errors: errs}}}
+	}
+	return discussion, nil
+}
+
+// FetchPRDiscussions returns the threads of the limit most recent PRs.
+// If some PRs could only be fetched partially, the discussions are still
+// returned together with a *PartialError describing what failed. When ctx
+// is cancelled, the PRs completed so far are returned and the
+// *PartialError wraps ctx.Err().
+func (a *Analyzer) FetchPRDiscussions(ctx context.Context, owner, repo string, limit int) ([]*PRDiscussion, error) {
+	prs, err := a.FetchPullRequests(ctx, owner, repo, limit)
+	var listErr *PartialError
+	if err != nil && !errors.As(err, &listErr) {
+		return nil, fmt.Errorf("error fetching pull requests: %w", err)
+	}
+
+	// Workers write into their PR's slot so output order matches prs.
+	discussions := make([]*PRDiscussion, len(prs))
+	failures := make([][]error, len(prs))
+	jobs := make(chan int)
+
+	workers := a.concurrency
+	if workers > len(prs) {
+		workers = len(prs)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				discussions[i], failures[i] = a.fetchDiscussion(ctx, owner, repo, prs[i], nil)
+			}
+		}()
+	}
+
+dispatch:
+	for i := range prs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
 	}
+	close(jobs)
+	wg.Wait()
 
-	var discussions []*PRDiscussion
-	for _, pr := range prs {
-		isMerged := pr.Merged
+	partial := &PartialError{Total: len(prs)}
 
-		discussion := &PRDiscussion{
-			PRNumber: pr.Number,
-			Title:    pr.Title,
-			Author:   pr.Author,
-			State:    pr.State,
-			Merged:   isMerged,
+	if ctx.Err() != nil {
+		// Keep only the PRs that finished before cancellation; the others
+		// would show truncated threads.
+		partial.Cause = ctx.Err()
+		if listErr != nil {
+			partial.Total = listErr.Total
 		}
+		var completed []*PRDiscussion
+		for i, d := range discussions {
+			if d != nil && len(failures[i]) == 0 {
+				completed = append(completed, d)
+			}
+		}
+		return completed, partial
+	}
+
+	for i, errs := range failures {
+		if len(errs) > 0 {
+			partial.Failures = append(partial.Failures, PRFailure{PRNumber: prs[i].Number, Errors: errs})
+		}
+	}
+	if listErr != nil {
+		// Only some PRs could be listed; report the threads of those.
+		partial.Total = listErr.Total
+		partial.Cause = listErr.Cause
+	}
+	if len(partial.Failures) > 0 || partial.Cause != nil {
+		return discussions, partial
+	}
 
-		ctx := context.Background()
-		prDetail, _, _ := a.client.PullRequests.Get(ctx, owner, repo, pr.Number)
-		if prDetail != nil && prDetail.Body != nil && *prDetail.Body != "" {
+	return discussions, nil
+}
+
+// fetchDiscussion collects one PR's thread, fetching the PR itself unless
+// prDetail is given. Failed API calls are returned instead of aborting, so
+// the rest of the thread is still usable.
+func (a *Analyzer) fetchDiscussion(ctx context.Context, owner, repo string, pr *PRInfo, prDetail *github.PullRequest) (*PRDiscussion, []error) {
+	discussion := &PRDiscussion{
+		PRNumber: pr.Number,
+		Title:    pr.Title,
+		Author:   pr.Author,
+		State:    pr.State,
+		Merged:   pr.Merged,
+	}
+
+	var errs []error
+
+	if prDetail == nil {
+		var err error
+		prDetail, _, err = awaitRateLimit(ctx, a.maxWait, func() (*github.PullRequest, *github.Response, error) {
+			return a.client.PullRequests.Get(ctx, owner, repo, pr.Number)
+		})
+		if err != nil {
+			errs = append(errs, classify("get pull request", err))
+		}
+	}
+	if prDetail != nil && prDetail.Body != nil && *prDetail.Body != "" {
+		discussion.Messages = append(discussion.Messages, DiscussionMessage{
+			Kind:      KindDescription,
+			Author:    pr.Author,
+			Body:      *prDetail.Body,
+			CreatedAt: prDetail.GetCreatedAt().Time,
+		})
+	}
+
+	comments, err := listAll(ctx, a.maxWait, func(opts github.ListOptions) ([]*github.IssueComment, *github.Response, error) {
+		return a.client.Issues.ListComments(ctx, owner, repo, pr.Number, &github.IssueListCommentsOptions{ListOptions: opts})
+	})
+	if err != nil {
+		errs = append(errs, classify("list comments", err))
+	}
+	for _, comment := range comments {
+		if comment.Body != nil && *comment.Body != "" {
+			author := ""
+			if comment.User != nil && comment.User.Login != nil {
+				author = *comment.User.Login
+			}
 			discussion.Messages = append(discussion.Messages, DiscussionMessage{
-				Author:    pr.Author,
-				Body:      *prDetail.Body,
-				CreatedAt: prDetail.CreatedAt.Format("2006-01-02 15:04:05"),
-				IsPRBody:  true,
+				Kind:      KindComment,
+				Author:    author,
+				Body:      *comment.Body,
+				CreatedAt: comment.GetCreatedAt().Time,
 			})
 		}
+	}
 
-		comments, _, _ := a.client.Issues.ListComments(ctx, owner, repo, pr.Number, nil)
-		for _, comment := range comments {
-			if comment.Body != nil && *comment.Body != "" {
-				author := ""
-				if comment.User != nil && comment.User.Login != nil {
-					author = *comment.User.Login
-				}
-				discussion.Messages = append(discussion.Messages, DiscussionMessage{
-					Author:    author,
-					Body:      *comment.Body,
-					CreatedAt: comment.CreatedAt.Format("2006-01-02 15:04:05"),
-				})
-			}
+	reviews, err := listAll(ctx, a.maxWait, func(opts github.ListOptions) ([]*github.PullRequestReview, *github.Response, error) {
+		return a.client.PullRequests.ListReviews(ctx, owner, repo, pr.Number, &opts)
+	})
+	if err != nil {
+		errs = append(errs, classify("list reviews", err))
+	}
+	for _, review := range reviews {
+		// Reviews without a summary only matter when they carry a verdict;
+		// an empty COMMENTED review just wraps line comments.
+		state := strings.ToUpper(review.GetState())
+		if state == "PENDING" || (review.GetBody() == "" && state == "COMMENTED") {
+			continue
 		}
+		discussion.Messages = append(discussion.Messages, DiscussionMessage{
+			Kind:        KindReview,
+			Author:      review.GetUser().GetLogin(),
+			Body:        review.GetBody(),
+			CreatedAt:   review.GetSubmittedAt().Time,
+			ReviewState: state,
+		})
+	}
 
-		reviewComments, _, _ := a.client.PullRequests.ListComments(ctx, owner, repo, pr.Number, nil)
-		for _, comment := range reviewComments {
-			if comment.Body != nil && *comment.Body != "" {
-				author := ""
-				if comment.User != nil && comment.User.Login != nil {
-					author = *comment.User.Login
-				}
-				discussion.Messages = append(discussion.Messages, DiscussionMessage{
-					Author:    author,
-					Body:      *comment.Body,
-					CreatedAt: comment.CreatedAt.Format("2006-01-02 15:04:05"),
-				})
+	reviewComments, err := listAll(ctx, a.maxWait, func(opts github.ListOptions) ([]*github.PullRequestComment, *github.Response, error) {
+		return a.client.PullRequests.ListComments(ctx, owner, repo, pr.Number, &github.PullRequestListCommentsOptions{ListOptions: opts})
+	})
+	if err != nil {
+		errs = append(errs, classify("list review comments", err))
+	}
+	for _, comment := range reviewComments {
+		if comment.Body != nil && *comment.Body != "" {
+			author := ""
+			if comment.User != nil && comment.User.Login != nil {
+				author = *comment.User.Login
 			}
+			// Comments on outdated diffs only have an original line.
+			line := comment.GetLine()
+			if line == 0 {
+				line = comment.GetOriginalLine()
+			}
+			discussion.Messages = append(discussion.Messages, DiscussionMessage{
+				Kind:      KindReviewComment,
+				Author:    author,
+				Body:      *comment.Body,
+				CreatedAt: comment.GetCreatedAt().Time,
+				ID:        comment.GetID(),
+				Path:      comment.GetPath(),
+				Line:      line,
+				DiffHunk:  comment.GetDiffHunk(),
+				InReplyTo: comment.GetInReplyTo(),
+			})
 		}
+	}
+
+	// Each source is already in chronological order; a stable sort merges
+	// them without reordering messages posted at the same second.
+	sort.SliceStable(discussion.Messages, func(i, j int) bool {
+		return discussion.Messages[i].CreatedAt.Before(discussion.Messages[j].CreatedAt)
+	})
+
+	return discussion, errs
+}
 
-		discussions = append(discussions, discussion)
+// FilterByTime keeps the messages posted at or after since and before
+// until; a zero bound is open. Discussions left without messages are
+// dropped.
+func FilterByTime(discussions []*PRDiscussion, since, until time.Time) []*PRDiscussion {
+	if since.IsZero() && until.IsZero() {
+		return discussions
 	}
 
-	return discussions, nil
+	var filtered []*PRDiscussion
+	for _, d := range discussions {
+		var messages []DiscussionMessage
+		for _, msg := range d.Messages {
+			if !since.IsZero() && msg.CreatedAt.Before(since) {
+				continue
+			}
+			if !until.IsZero() && !msg.CreatedAt.Before(until) {
+				continue
+			}
+			messages = append(messages, msg)
+		}
+		if len(messages) == 0 {
+			continue
+		}
+
+		kept := *d
+		kept.Messages = messages
+		filtered = append(filtered, &kept)
+	}
+	return filtered
 }
 
-func createGitHubClient(token string) *github.Client {
-	ctx := context.Background()
+// maxPerPage is the largest page size the GitHub REST API accepts.
+const maxPerPage = 100
 
-	// Check token from parameter first, then environment
-	githubToken := token
-	if githubToken == "" {
-		githubToken = os.Getenv("GITHUB_TOKEN")
+// listAll follows Link headers until every page returned by fetch is read,
+// waiting out exhausted rate limits like awaitRateLimit.
+func listAll[T any](ctx context.Context, maxWait time.Duration, fetch func(opts github.ListOptions) ([]T, *github.Response, error)) ([]T, error) {
+	opts := github.ListOptions{PerPage: maxPerPage}
+
+	var all []T
+	for {
+		items, resp, err := awaitRateLimit(ctx, maxWait, func() ([]T, *github.Response, error) {
+			return fetch(opts)
+		})
+		if err != nil {
+			return all, err
+		}
+		all = append(all, items...)
+
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opts.Page = resp.NextPage
 	}
+}
 
-	if githubToken == "" {
-		fmt.Println("Warning: No GitHub token provided. Using unauthenticated client (rate limited)")
-		fmt.Println("Set GITHUB_TOKEN environment variable or use --token flag")
-		return github.NewClient(nil)
+func createGitHubClient(ts oauth2.TokenSource, baseURL string, transport http.RoundTripper) (*github.Client, error) {
+	ctx := context.Background()
+	httpClient := &http.Client{Transport: transport}
+
+	if ts == nil {
+		fmt.Fprintln(os.Stderr, "Warning: No GitHub token provided. Using unauthenticated client (rate limited)")
+		fmt.Fprintln(os.Stderr, "Set GITHUB_TOKEN environment variable or use --token flag")
+		return withBaseURL(github.NewClient(httpClient), baseURL)
 	}
 
 	// Create authenticated client
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: githubToken},
-	)
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
 	tc := oauth2.NewClient(ctx, ts)
-	return github.NewClient(tc)
+	return withBaseURL(github.NewClient(tc), baseURL)
+}
+
+// withBaseURL configures client for a GitHub Enterprise Server instance.
+// go-github appends /api/v3/ and /api/uploads/ when they are missing.
+func withBaseURL(client *github.Client, baseURL string) (*github.Client, error) {
+	if baseURL == "" {
+		return client, nil
+	}
+
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		baseURL = "https://" + baseURL
+	}
+	apiURL := strings.TrimSuffix(baseURL, "/")
+	uploadURL := strings.TrimSuffix(apiURL, "/api/v3") + "/api/uploads/"
+
+	client, err := client.WithEnterpriseURLs(apiURL, uploadURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub URL %q: %v", baseURL, err)
+	}
+	return client, nil
 }
 
 // Helper functions
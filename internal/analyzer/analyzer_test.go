@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// pullsServer serves one page of two PRs, linking to a second page that
+// fails with status.
+func pullsServer(status int) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"message": "Not Found"}`))
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=2&per_page=2>; rel="next"`, srv.URL, r.URL.Path))
+		w.Write([]byte(`[{"number": 2, "title": "second", "state": "open"}, {"number": 1, "title": "first", "state": "closed"}]`))
+	}))
+	return srv
+}
+
+func TestFetchPullRequestsKeepsFetchedPages(t *testing.T) {
+	srv := pullsServer(http.StatusNotFound)
+	defer srv.Close()
+
+	a := newTestAnalyzer(t, srv.URL, time.Minute)
+	prs, err := a.FetchPullRequests(context.Background(), "o", "r", 4)
+
+	var partial *PartialError
+	if !errors.As(err, &partial) {
+		t.Fatalf("error = %v, want a *PartialError", err)
+	}
+	if partial.Total != 4 || !errors.Is(err, ErrNotFound) {
+		t.Errorf("PartialError = %+v, want 4 PRs and a not found cause", partial)
+	}
+	if len(prs) != 2 || prs[0].Number != 2 || prs[1].Number != 1 {
+		t.Errorf("got %d PRs, want #2 and #1 from the first page", len(prs))
+	}
+}
+
+func TestFetchPullRequestsFirstPageFails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Not Found"}`))
+	}))
+	defer srv.Close()
+
+	a := newTestAnalyzer(t, srv.URL, time.Minute)
+	prs, err := a.FetchPullRequests(context.Background(), "o", "r", 4)
+
+	var partial *PartialError
+	if errors.As(err, &partial) || !errors.Is(err, ErrNotFound) || prs != nil {
+		t.Errorf("FetchPullRequests() = %v, %v; want nil and a not found error", prs, err)
+	}
+}
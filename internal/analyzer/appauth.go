@@ -0,0 +1,179 @@
+package analyzer
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// AppCredentials identifies a GitHub App installation to authenticate as.
+type AppCredentials struct {
+	AppID          int64
+	InstallationID int64
+	PrivateKeyPath string
+}
+
+// WithAppAuth authenticates as a GitHub App installation instead of with a
+// personal access token. Installation tokens are refreshed automatically.
+func WithAppAuth(creds AppCredentials) Option {
+	return func(a *Analyzer) {
+		a.app = &creds
+	}
+}
+
+// newAppTokenSource returns a token source that exchanges a signed app JWT
+// for installation tokens, reusing each token until shortly before expiry.
+func newAppTokenSource(creds AppCredentials, baseURL string, transport http.RoundTripper) (*appTokenSource, error) {
+	if creds.AppID == 0 || creds.InstallationID == 0 || creds.PrivateKeyPath == "" {
+		return nil, fmt.Errorf("GitHub App authentication needs an app ID, installation ID and private key path")
+	}
+
+	pemData, err := os.ReadFile(creds.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub App private key: %v", err)
+	}
+	key, err := parseRSAPrivateKey(pemData)
+	if err != nil {
+		return nil, err
+	}
+
+	jt := &jwtTransport{base: transport, appID: creds.AppID, key: key}
+	client, err := withBaseURL(github.NewClient(&http.Client{Transport: jt}), baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &appTokenSource{client: client, installationID: creds.InstallationID}, nil
+}
+
+// tokenExpiryDelta is how long before expiry an installation token is
+// replaced, so requests in flight never carry an expired token.
+const tokenExpiryDelta = time.Minute
+
+type appTokenSource struct {
+	client         *github.Client
+	installationID int64
+
+	mu  sync.Mutex
+	tok *github.InstallationToken
+}
+
+// Token returns the current installation token, exchanging a new one when
+// it is about to expire. The exchange is bound to ctx, so it is cancelled
+// together with the request that needed it.
+func (s *appTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tok != nil && time.Until(s.tok.GetExpiresAt().Time) > tokenExpiryDelta {
+		return s.tok.GetToken(), nil
+	}
+
+	tok, _, err := s.client.Apps.CreateInstallationToken(ctx, s.installationID, nil)
+	if err != nil {
+		return "", classify("create installation token", err)
+	}
+	s.tok = tok
+	return tok.GetToken(), nil
+}
+
+// appTransport authorizes requests with the app's installation token.
+type appTransport struct {
+	base http.RoundTripper
+	src  *appTokenSource
+}
+
+func (t *appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.src.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "token "+token)
+	return t.base.RoundTrip(req)
+}
+
+// jwtTransport authenticates requests as the app itself with a short-lived
+// RS256 JWT, as required by the installation token endpoint.
+type jwtTransport struct {
+	base  http.RoundTripper
+	appID int64
+	key   *rsa.PrivateKey
+}
+
+func (t *jwtTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := signAppJWT(t.appID, t.key, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(req)
+}
+
+// signAppJWT builds the JWT GitHub expects from an app: issued a minute in
+// the past to allow for clock drift and valid for the maximum of 10 minutes.
+func signAppJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-60 * time.Second).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": strconv.FormatInt(appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %v", err)
+	}
+
+	return signingInput + "." + enc.EncodeToString(sig), nil
+}
+
+// parseRSAPrivateKey accepts the PKCS#1 PEM GitHub generates as well as
+// PKCS#8 keys.
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %v", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key must be an RSA key")
+	}
+	return key, nil
+}
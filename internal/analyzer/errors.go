@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// Sentinel kinds for classified GitHub API failures. Test with errors.Is.
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrForbidden   = errors.New("forbidden")
+	ErrRateLimited = errors.New("rate limited")
+	ErrNetwork     = errors.New("network error")
+)
+
+// APIError is a GitHub API failure tagged with the operation that caused it
+// and, when recognised, one of the Err* kinds.
+type APIError struct {
+	Op   string
+	Kind error
+	Err  error
+}
+
+func (e *APIError) Error() string {
+	if e.Kind != nil {
+		return fmt.Sprintf("%s: %v: %v", e.Op, e.Kind, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
+func (e *APIError) Unwrap() error { return e.Err }
+
+func (e *APIError) Is(target error) bool {
+	return e.Kind != nil && target == e.Kind
+}
+
+// classify wraps err in an APIError describing op. It returns nil for nil.
+func classify(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	apiErr := &APIError{Op: op, Err: err}
+
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	var respErr *github.ErrorResponse
+	var urlErr *url.Error
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &rateErr), errors.As(err, &abuseErr):
+		apiErr.Kind = ErrRateLimited
+	case errors.As(err, &respErr) && respErr.Response != nil:
+		if _, limited := rateLimitWait(respErr.Response, time.Now()); limited {
+			apiErr.Kind = ErrRateLimited
+			break
+		}
+		switch respErr.Response.StatusCode {
+		case http.StatusNotFound:
+			apiErr.Kind = ErrNotFound
+		case http.StatusUnauthorized, http.StatusForbidden:
+			apiErr.Kind = ErrForbidden
+		case http.StatusTooManyRequests:
+			apiErr.Kind = ErrRateLimited
+		}
+	case errors.As(err, &urlErr), errors.As(err, &netErr):
+		apiErr.Kind = ErrNetwork
+	}
+
+	return apiErr
+}
+
+// PRFailure lists the API calls that failed while fetching one PR.
+type PRFailure struct {
+	PRNumber int
+	Errors   []error
+}
+
+// PartialError is returned together with results when some PRs could only
+// be fetched partially. Their discussions may be missing messages. Cause is
+// set when the fetch was cut short by cancellation or timeout.
+type PartialError struct {
+	Total    int
+	Failures []PRFailure
+	Cause    error
+}
+
+func (e *PartialError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("interrupted before all %d PRs were fetched: %v", e.Total, e.Cause)
+	}
+
+	numbers := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		numbers = append(numbers, fmt.Sprintf("#%d", f.PRNumber))
+	}
+	return fmt.Sprintf("%d of %d PRs fetched incompletely (%s)", len(e.Failures), e.Total, strings.Join(numbers, ", "))
+}
+
+// Unwrap exposes every per-PR error so errors.Is can match their kinds.
+func (e *PartialError) Unwrap() []error {
+	var errs []error
+	if e.Cause != nil {
+		errs = append(errs, e.Cause)
+	}
+	for _, f := range e.Failures {
+		errs = append(errs, f.Errors...)
+	}
+	return errs
+}
@@ -0,0 +1,27 @@
+package analyzer
+
+import "time"
+
+// HealthReport summarises the sentiment of PR discussions.
+type HealthReport struct {
+	PRCount          int
+	MessageCount     int
+	FailedCount      int
+	InterruptedCount int // skipped because the run was cancelled or timed out
+	PositiveScore    float64
+	NegativeScore    float64
+	NeutralScore     float64
+	AverageSentiment float64
+	Messages         []MessageAnalysis
+}
+
+// MessageAnalysis is the sentiment of one discussion message.
+type MessageAnalysis struct {
+	PRNumber  int
+	Author    string
+	Kind      MessageKind
+	CreatedAt time.Time
+	Content   string
+	Sentiment string
+	Score     float64
+}
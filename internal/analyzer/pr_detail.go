@@ -0,0 +1,212 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// PRDetail is everything known about a single pull request.
+type PRDetail struct {
+	Number             int
+	Title              string
+	State              string
+	Author             string
+	Merged             bool
+	Draft              bool
+	URL                string
+	BaseRef            string
+	HeadRef            string
+	CreatedAt          time.Time
+	Body               string
+	Labels             []string
+	Assignees          []string
+	RequestedReviewers []string
+	Reviews            []ReviewState
+	Checks             []CheckResult
+	Files              []FileChange
+	Additions          int
+	Deletions          int
+	Discussion         *PRDiscussion
+}
+
+// ReviewState is a reviewer's current verdict on a PR: APPROVED,
+// CHANGES_REQUESTED, COMMENTED or DISMISSED.
+type ReviewState struct {
+	Reviewer    string
+	State       string
+	SubmittedAt time.Time
+}
+
+// CheckResult is one CI result for the PR's head commit, from either the
+// Checks API or a commit status.
+type CheckResult struct {
+	Name  string
+	State string
+	URL   string
+}
+
+// FileChange is one file touched by a PR.
+type FileChange struct {
+	Filename  string
+	Status    string
+	Additions int
+	Deletions int
+}
+
+// FetchPRDetail returns a single PR with its reviews, CI results, changed
+// files and discussion thread. Sections that could not be fetched are left
+// empty and reported in a *PartialError alongside the detail.
+func (a *Analyzer) FetchPRDetail(ctx context.Context, owner, repo string, number int) (*PRDetail, error) {
+	pr, _, err := awaitRateLimit(ctx, a.maxWait, func() (*github.PullRequest, *github.Response, error) {
+		return a.client.PullRequests.Get(ctx, owner, repo, number)
+	})
+	if err != nil {
+		return nil, classify(fmt.Sprintf("get pull request #%d", number), err)
+	}
+
+	info := newPRInfo(pr)
+	detail := &PRDetail{
+		Number:    info.Number,
+		Title:     info.Title,
+		State:     info.State,
+		Author:    info.Author,
+		Merged:    info.Merged,
+		Draft:     pr.GetDraft(),
+		URL:       pr.GetHTMLURL(),
+		BaseRef:   pr.GetBase().GetRef(),
+		HeadRef:   pr.GetHead().GetRef(),
+		CreatedAt: pr.GetCreatedAt().Time,
+		Body:      pr.GetBody(),
+	}
+
+	for _, label := range pr.Labels {
+		detail.Labels = append(detail.Labels, label.GetName())
+	}
+	for _, user := range pr.Assignees {
+		detail.Assignees = append(detail.Assignees, user.GetLogin())
+	}
+	for _, user := range pr.RequestedReviewers {
+		detail.RequestedReviewers = append(detail.RequestedReviewers, user.GetLogin())
+	}
+	for _, team := range pr.RequestedTeams {
+		detail.RequestedReviewers = append(detail.RequestedReviewers, "@"+team.GetSlug())
+	}
+
+	var errs []error
+
+	files, err := listAll(ctx, a.maxWait, func(opts github.ListOptions) ([]*github.CommitFile, *github.Response, error) {
+		return a.client.PullRequests.ListFiles(ctx, owner, repo, number, &opts)
+	})
+	if err != nil {
+		errs = append(errs, classify("list files", err))
+	}
+	for _, f := range files {
+		detail.Files = append(detail.Files, FileChange{
+			Filename:  f.GetFilename(),
+			Status:    f.GetStatus(),
+			Additions: f.GetAdditions(),
+			Deletions: f.GetDeletions(),
+		})
+		detail.Additions += f.GetAdditions()
+		detail.Deletions += f.GetDeletions()
+	}
+
+	if sha := pr.GetHead().GetSHA(); sha != "" {
+		checks, checkErrs := a.fetchChecks(ctx, owner, repo, sha)
+		detail.Checks = checks
+		errs = append(errs, checkErrs...)
+	}
+
+	discussion, discussionErrs := a.fetchDiscussion(ctx, owner, repo, info, pr)
+	detail.Discussion = discussion
+	detail.Reviews = latestReviews(discussion.Messages)
+	errs = append(errs, discussionErrs...)
+
+	if len(errs) > 0 {
+		return detail, &PartialError{Total: 1, Failures: []PRFailure{{PRNumber: number, Errors: errs}}}
+	}
+	return detail, nil
+}
+
+// latestReviews keeps each reviewer's most recent verdict from the review
+// messages of a thread. A later comment does not override an earlier
+// approval or change request, matching how GitHub shows review status.
+func latestReviews(messages []DiscussionMessage) []ReviewState {
+	var states []ReviewState
+	index := make(map[string]int)
+
+	for _, msg := range messages {
+		if msg.Kind != KindReview {
+			continue
+		}
+
+		review := ReviewState{
+			Reviewer:    msg.Author,
+			State:       msg.ReviewState,
+			SubmittedAt: msg.CreatedAt,
+		}
+
+		i, seen := index[review.Reviewer]
+		switch {
+		case !seen:
+			index[review.Reviewer] = len(states)
+			states = append(states, review)
+		case review.State != "COMMENTED" || states[i].State == "COMMENTED":
+			states[i] = review
+		}
+	}
+
+	return states
+}
+
+// fetchChecks collects check runs and commit statuses for ref.
+func (a *Analyzer) fetchChecks(ctx context.Context, owner, repo, ref string) ([]CheckResult, []error) {
+	var checks []CheckResult
+	var errs []error
+
+	opts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: maxPerPage}}
+	for {
+		runs, resp, err := awaitRateLimit(ctx, a.maxWait, func() (*github.ListCheckRunsResults, *github.Response, error) {
+			return a.client.Checks.ListCheckRunsForRef(ctx, owner, repo, ref, opts)
+		})
+		if err != nil {
+			errs = append(errs, classify("list check runs", err))
+			break
+		}
+		for _, run := range runs.CheckRuns {
+			state := run.GetConclusion()
+			if state == "" {
+				state = run.GetStatus()
+			}
+			checks = append(checks, CheckResult{
+				Name:  run.GetName(),
+				State: state,
+				URL:   run.GetHTMLURL(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	status, _, err := awaitRateLimit(ctx, a.maxWait, func() (*github.CombinedStatus, *github.Response, error) {
+		return a.client.Repositories.GetCombinedStatus(ctx, owner, repo, ref, &github.ListOptions{PerPage: maxPerPage})
+	})
+	if err != nil {
+		errs = append(errs, classify("get commit status", err))
+	} else {
+		for _, s := range status.Statuses {
+			checks = append(checks, CheckResult{
+				Name:  s.GetContext(),
+				State: s.GetState(),
+				URL:   s.GetTargetURL(),
+			})
+		}
+	}
+
+	return checks, errs
+}
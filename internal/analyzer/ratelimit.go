@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// RateLimit is the quota state of one GitHub API resource.
+type RateLimit struct {
+	Resource  string
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// Usage summarises the API calls made by an Analyzer.
+type Usage struct {
+	// Calls is the number of HTTP requests sent to GitHub.
+	Calls int64
+	// NotModified counts revalidated cache hits, which are free.
+	NotModified int64
+	// Remaining is the last reported core quota, or -1 if unknown.
+	Remaining int64
+}
+
+// Usage returns the API calls made so far.
+func (a *Analyzer) Usage() Usage {
+	return Usage{
+		Calls:       a.usage.calls.Load(),
+		NotModified: a.usage.notModified.Load(),
+		Remaining:   a.usage.remaining.Load(),
+	}
+}
+
+// FetchRateLimits returns the current quota for each API resource. The
+// request itself does not count against the rate limit.
+func (a *Analyzer) FetchRateLimits(ctx context.Context) ([]RateLimit, error) {
+	limits, _, err := a.client.RateLimits(ctx)
+	if err != nil {
+		return nil, classify("get rate limits", err)
+	}
+
+	var result []RateLimit
+	add := func(resource string, r *github.Rate) {
+		if r == nil {
+			return
+		}
+		result = append(result, RateLimit{
+			Resource:  resource,
+			Limit:     r.Limit,
+			Remaining: r.Remaining,
+			Reset:     r.Reset.Time,
+		})
+	}
+	add("core", limits.Core)
+	add("search", limits.Search)
+	add("graphql", limits.GraphQL)
+
+	return result, nil
+}
@@ -0,0 +1,138 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RepoRef identifies a repository and, optionally, a PR or issue in it.
+type RepoRef struct {
+	// Host is "github.com" or the host[:port] of a GitHub Enterprise Server.
+	Host  string
+	Owner string
+	Name  string
+	// Number is the PR or issue number from the URL, or 0.
+	Number int
+}
+
+func (r *RepoRef) String() string {
+	if r.Number != 0 {
+		return fmt.Sprintf("%s/%s#%d", r.Owner, r.Name, r.Number)
+	}
+	return r.Owner + "/" + r.Name
+}
+
+var (
+	// Owners: 1-39 alphanumerics or hyphens, not starting or ending with a hyphen.
+	ownerRe = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,37}[A-Za-z0-9])?$`)
+	// Repositories: up to 100 alphanumerics, hyphens, underscores or dots.
+	repoNameRe = regexp.MustCompile(`^[A-Za-z0-9._-]{1,100}$`)
+)
+
+// ParseRepoURL parses a repository reference in any of the common forms:
+//
+//	owner/repo
+//	owner/repo#123
+//	github.com/owner/repo, www.github.com/owner/repo
+//	https://github.com/owner/repo.git
+//	https://github.com/owner/repo/pull/123 (or /issues/123, /tree/main/...)
+//	git@github.com:owner/repo.git, ssh://git@github.com/owner/repo.git
+//	ssh://git@ssh.github.com:443/owner/repo.git
+//	https://ghe.example.com/api/v3/repos/owner/repo
+func ParseRepoURL(raw string) (*RepoRef, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid repository %q: %s. Use 'owner/repo' or full GitHub URL", raw, reason)
+	}
+
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return nil, invalid("empty reference")
+	}
+
+	ref := &RepoRef{Host: "github.com"}
+
+	// Strip the scheme and any user info, remembering whether a host follows.
+	hasHost, ssh := false, false
+	if i := strings.Index(s, "://"); i >= 0 {
+		ssh = strings.EqualFold(s[:i], "ssh") || strings.EqualFold(s[:i], "git+ssh")
+		s = s[i+3:]
+		hasHost = true
+	} else if at := strings.Index(s, "@"); at >= 0 && strings.Contains(s[at:], ":") {
+		// scp-like SSH syntax: git@host:owner/repo.git
+		s = strings.Replace(s[at+1:], ":", "/", 1)
+		hasHost, ssh = true, true
+	}
+	if at := strings.Index(s, "@"); hasHost && at >= 0 && at < strings.Index(s+"/", "/") {
+		s = s[at+1:]
+	}
+
+	// Drop query strings and fragments, keeping "#123" on short references.
+	if i := strings.IndexAny(s, "?"); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.Index(s, "#"); i >= 0 {
+		if n, err := strconv.Atoi(s[i+1:]); err == nil && n > 0 {
+			ref.Number = n
+		}
+		s = s[:i]
+	}
+
+	parts := strings.Split(strings.Trim(s, "/"), "/")
+
+	// GitHub owners cannot contain dots, so a dotted first segment is a host.
+	if hasHost || strings.Contains(parts[0], ".") {
+		host := strings.ToLower(parts[0])
+		if ssh {
+			// SSH ports (22, or 443 for ssh.github.com) say nothing about
+			// where the API is served.
+			if i := strings.LastIndex(host, ":"); i >= 0 {
+				host = host[:i]
+			}
+		}
+		switch host {
+		case "www.github.com", "api.github.com", "ssh.github.com":
+			host = "github.com"
+		}
+		ref.Host = host
+		parts = parts[1:]
+
+		if len(parts) >= 2 && parts[0] == "api" && parts[1] == "v3" {
+			parts = parts[2:]
+		}
+		if len(parts) > 0 && parts[0] == "repos" {
+			parts = parts[1:]
+		}
+	}
+
+	if ref.Host == "" {
+		return nil, invalid("missing host")
+	}
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return nil, invalid("missing owner or repository name")
+	}
+
+	ref.Owner = parts[0]
+	ref.Name = strings.TrimSuffix(parts[1], ".git")
+
+	if len(parts) >= 4 {
+		switch parts[2] {
+		case "pull", "pulls", "issues":
+			n, err := strconv.Atoi(parts[3])
+			if err != nil || n <= 0 {
+				return nil, invalid(fmt.Sprintf("bad %s number %q", parts[2], parts[3]))
+			}
+			ref.Number = n
+		}
+	}
+
+	if !ownerRe.MatchString(ref.Owner) {
+		return nil, invalid(fmt.Sprintf("%q is not a valid GitHub owner name", ref.Owner))
+	}
+	if !repoNameRe.MatchString(ref.Name) || ref.Name == "." || ref.Name == ".." {
+		return nil, invalid(fmt.Sprintf("%q is not a valid GitHub repository name", ref.Name))
+	}
+
+	return ref, nil
+}
@@ -0,0 +1,68 @@
+package analyzer
+
+import "testing"
+
+func TestParseRepoURL(t *testing.T) {
+	tests := []struct {
+		in     string
+		host   string
+		owner  string
+		name   string
+		number int
+	}{
+		{"owner/repo", "github.com", "owner", "repo", 0},
+		{"owner/repo#123", "github.com", "owner", "repo", 123},
+		{" owner/repo ", "github.com", "owner", "repo", 0},
+		{"github.com/owner/repo", "github.com", "owner", "repo", 0},
+		{"www.github.com/owner/repo", "github.com", "owner", "repo", 0},
+		{"https://github.com/owner/repo.git", "github.com", "owner", "repo", 0},
+		{"https://github.com/owner/repo/", "github.com", "owner", "repo", 0},
+		{"https://github.com/owner/repo?tab=readme", "github.com", "owner", "repo", 0},
+		{"https://github.com/owner/repo/pull/123", "github.com", "owner", "repo", 123},
+		{"https://github.com/owner/repo/pull/123/files#diff-1", "github.com", "owner", "repo", 123},
+		{"https://github.com/owner/repo/issues/7", "github.com", "owner", "repo", 7},
+		{"https://github.com/owner/repo/tree/main/cmd", "github.com", "owner", "repo", 0},
+		{"https://api.github.com/repos/owner/repo", "github.com", "owner", "repo", 0},
+		{"git@github.com:owner/repo.git", "github.com", "owner", "repo", 0},
+		{"ssh://git@github.com/owner/repo.git", "github.com", "owner", "repo", 0},
+		{"ssh://git@github.com:22/owner/repo.git", "github.com", "owner", "repo", 0},
+		{"ssh://git@ssh.github.com:443/owner/repo.git", "github.com", "owner", "repo", 0},
+		{"git+ssh://git@github.com:22/owner/repo.git", "github.com", "owner", "repo", 0},
+		{"git@ghe.example.com:owner/repo.git", "ghe.example.com", "owner", "repo", 0},
+		{"ssh://git@ghe.example.com:2222/owner/repo.git", "ghe.example.com", "owner", "repo", 0},
+		{"https://ghe.example.com/owner/repo", "ghe.example.com", "owner", "repo", 0},
+		{"https://ghe.example.com:8443/owner/repo", "ghe.example.com:8443", "owner", "repo", 0},
+		{"https://ghe.example.com/api/v3/repos/owner/repo", "ghe.example.com", "owner", "repo", 0},
+		{"owner/my.repo_name-2", "github.com", "owner", "my.repo_name-2", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			ref, err := ParseRepoURL(tt.in)
+			if err != nil {
+				t.Fatalf("ParseRepoURL(%q): %v", tt.in, err)
+			}
+			if ref.Host != tt.host || ref.Owner != tt.owner || ref.Name != tt.name || ref.Number != tt.number {
+				t.Errorf("ParseRepoURL(%q) = %+v, want {Host:%s Owner:%s Name:%s Number:%d}",
+					tt.in, *ref, tt.host, tt.owner, tt.name, tt.number)
+			}
+		})
+	}
+}
+
+func TestParseRepoURLInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"owner",
+		"owner/",
+		"https://github.com/owner",
+		"https://github.com/owner/repo/pull/abc",
+		"-owner/repo",
+		"owner/..",
+		"own_er/repo",
+	} {
+		if ref, err := ParseRepoURL(in); err == nil {
+			t.Errorf("ParseRepoURL(%q) = %+v, want error", in, *ref)
+		}
+	}
+}
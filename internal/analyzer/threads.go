@@ -0,0 +1,60 @@
+package analyzer
+
+import "sort"
+
+// ReviewThread is a conversation on one line of a PR's diff: the comment
+// that started it followed by its replies.
+type ReviewThread struct {
+	Path     string
+	Line     int
+	DiffHunk string
+	Comments []DiscussionMessage
+}
+
+// ReviewThreads groups the discussion's review comments into threads,
+// ordered by file and line. GitHub points every reply at the comment that
+// started the thread; replies whose root is missing start their own thread.
+func (d *PRDiscussion) ReviewThreads() []ReviewThread {
+	var threads []ReviewThread
+	index := make(map[int64]int)
+
+	for _, msg := range d.Messages {
+		if msg.Kind != KindReviewComment {
+			continue
+		}
+
+		if i, ok := index[msg.InReplyTo]; ok && msg.InReplyTo != 0 {
+			threads[i].Comments = append(threads[i].Comments, msg)
+			index[msg.ID] = i
+			continue
+		}
+
+		index[msg.ID] = len(threads)
+		threads = append(threads, ReviewThread{
+			Path:     msg.Path,
+			Line:     msg.Line,
+			DiffHunk: msg.DiffHunk,
+			Comments: []DiscussionMessage{msg},
+		})
+	}
+
+	sort.SliceStable(threads, func(i, j int) bool {
+		if threads[i].Path != threads[j].Path {
+			return threads[i].Path < threads[j].Path
+		}
+		return threads[i].Line < threads[j].Line
+	})
+	return threads
+}
+
+// Conversation returns the messages that are not attached to the diff: the
+// description and top-level comments.
+func (d *PRDiscussion) Conversation() []DiscussionMessage {
+	var messages []DiscussionMessage
+	for _, msg := range d.Messages {
+		if msg.Kind != KindReviewComment {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
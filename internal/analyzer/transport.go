@@ -0,0 +1,211 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"repo-doc/internal/retry"
+
+	"github.com/google/go-github/v56/github"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// defaultRequestsPerSecond keeps well under GitHub's secondary rate limit
+	// of 900 REST points per minute.
+	defaultRequestsPerSecond = 10
+	defaultBurst             = 5
+)
+
+// limitedTransport blocks each request on a token-bucket limiter shared by
+// every worker of an Analyzer.
+type limitedTransport struct {
+	base    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// DefaultMaxWait is how long requests wait for an exhausted rate limit to
+// reset before failing.
+const DefaultMaxWait = 10 * time.Minute
+
+// rateLimitTransport counts API calls and, when GitHub reports an exhausted
+// primary or secondary rate limit, sleeps until the reset time (or for
+// Retry-After) and retries, as long as that is within maxWait.
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	maxWait time.Duration
+
+	calls       atomic.Int64
+	notModified atomic.Int64
+	remaining   atomic.Int64
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		t.record(resp)
+
+		wait, limited := rateLimitWait(resp, time.Now())
+		if !limited {
+			return resp, nil
+		}
+		if wait > t.maxWait || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+
+		log.Printf("GitHub rate limit exceeded, waiting %s before retrying", wait.Round(time.Second))
+		resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+func (t *rateLimitTransport) record(resp *http.Response) {
+	t.calls.Add(1)
+	if resp.StatusCode == http.StatusNotModified {
+		t.notModified.Add(1)
+	}
+	if remaining, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Remaining"), 10, 64); err == nil {
+		t.remaining.Store(remaining)
+	}
+}
+
+// rateLimitWait reports whether resp is a rate-limit rejection and how long
+// to wait before retrying. Secondary limits send Retry-After; an exhausted
+// primary limit sends X-RateLimit-Remaining: 0 and the reset time.
+func rateLimitWait(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if secs, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+		if at, err := http.ParseTime(retryAfter); err == nil {
+			return at.Sub(now), true
+		}
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		// A second of slack for clock skew between us and GitHub.
+		wait := time.Unix(reset, 0).Sub(now) + time.Second
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+// awaitRateLimit calls fn and, when go-github refuses to send the request
+// because a limit reported by an earlier response has not reset yet, sleeps
+// until the reset and calls fn again, as long as that is within maxWait.
+// Those requests never reach rateLimitTransport, which only sees limits
+// GitHub enforces itself.
+func awaitRateLimit[T any](ctx context.Context, maxWait time.Duration, fn func() (T, *github.Response, error)) (T, *github.Response, error) {
+	for {
+		v, resp, err := fn()
+
+		var rateErr *github.RateLimitError
+		var abuseErr *github.AbuseRateLimitError
+		var wait time.Duration
+		switch {
+		case errors.As(err, &rateErr):
+			// A second of slack for clock skew between us and GitHub.
+			wait = time.Until(rateErr.Rate.Reset.Time) + time.Second
+		case errors.As(err, &abuseErr) && abuseErr.RetryAfter != nil:
+			wait = *abuseErr.RetryAfter
+		default:
+			return v, resp, err
+		}
+		if wait > maxWait {
+			return v, resp, err
+		}
+
+		log.Printf("GitHub rate limit exceeded, waiting %s before retrying", wait.Round(time.Second))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return v, resp, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryTransport retries requests that fail with a 5xx status, a reset
+// connection or a timeout, using jittered exponential backoff.
+type retryTransport struct {
+	base    http.RoundTripper
+	retries int
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	replayable := req.Body == nil || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+
+		transient := (err != nil && retry.Transient(err)) ||
+			(err == nil && retry.TransientStatus(resp.StatusCode))
+		if !transient || attempt >= t.retries || !replayable || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		if err != nil {
+			log.Printf("GitHub request failed (%v), retrying", err)
+		} else {
+			log.Printf("GitHub returned %s, retrying", resp.Status)
+			resp.Body.Close()
+		}
+
+		if err := retry.Sleep(req.Context(), retry.Backoff(attempt)); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitWait(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name    string
+		status  int
+		header  map[string]string
+		wait    time.Duration
+		limited bool
+	}{
+		{"ok", http.StatusOK, map[string]string{"X-RateLimit-Remaining": "0"}, 0, false},
+		{"not found", http.StatusNotFound, nil, 0, false},
+		{"forbidden without limit headers", http.StatusForbidden, nil, 0, false},
+		{"retry after seconds", http.StatusForbidden, map[string]string{"Retry-After": "30"}, 30 * time.Second, true},
+		{"retry after date", http.StatusTooManyRequests, map[string]string{"Retry-After": now.Add(time.Minute).UTC().Format(http.TimeFormat)}, time.Minute, true},
+		{"primary exhausted", http.StatusForbidden, map[string]string{
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Reset":     strconv.FormatInt(now.Add(90*time.Second).Unix(), 10),
+		}, 91 * time.Second, true},
+		{"primary reset passed", http.StatusForbidden, map[string]string{
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Reset":     strconv.FormatInt(now.Add(-time.Minute).Unix(), 10),
+		}, 0, true},
+		{"primary bad reset", http.StatusForbidden, map[string]string{
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Reset":     "soon",
+		}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: make(http.Header)}
+			for k, v := range tt.header {
+				resp.Header.Set(k, v)
+			}
+
+			wait, limited := rateLimitWait(resp, now)
+			if wait != tt.wait || limited != tt.limited {
+				t.Errorf("rateLimitWait() = %v, %v; want %v, %v", wait, limited, tt.wait, tt.limited)
+			}
+		})
+	}
+}
+
+// repoHandler serves GET /repos/o/r, reporting the quota given by remaining
+// and reset, or a 403 rate-limit rejection when rejected returns true.
+func repoHandler(hits *atomic.Int64, remaining func() int, reset time.Time, rejected func() bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining()))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.Header().Set("Content-Type", "application/json")
+		if rejected() {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "API rate limit exceeded"}`))
+			return
+		}
+		w.Write([]byte(`{"name": "r", "full_name": "o/r"}`))
+	}
+}
+
+func newTestAnalyzer(t *testing.T, url string, maxWait time.Duration) *Analyzer {
+	t.Helper()
+	a, err := New("token", WithBaseURL(url), WithMaxWait(maxWait))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// After a response exhausts the quota, go-github refuses later requests
+// without sending them; they must wait for the reset instead of failing.
+func TestExhaustedQuotaWaitsForReset(t *testing.T) {
+	var hits atomic.Int64
+	reset := time.Now().Add(time.Second)
+	srv := httptest.NewServer(repoHandler(&hits,
+		func() int { return 0 },
+		reset,
+		func() bool { return false }))
+	defer srv.Close()
+
+	a := newTestAnalyzer(t, srv.URL, time.Minute)
+	ctx := context.Background()
+
+	if _, err := a.FetchRepoInfo(ctx, "o", "r"); err != nil {
+		t.Fatalf("first FetchRepoInfo: %v", err)
+	}
+	info, err := a.FetchRepoInfo(ctx, "o", "r")
+	if err != nil {
+		t.Fatalf("second FetchRepoInfo: %v", err)
+	}
+	if info.FullName != "o/r" {
+		t.Errorf("FullName = %q, want o/r", info.FullName)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("server saw %d requests, want 2", n)
+	}
+	if time.Now().Before(reset) {
+		t.Error("second request was sent before the rate limit reset")
+	}
+}
+
+func TestExhaustedQuotaBeyondMaxWaitFails(t *testing.T) {
+	var hits atomic.Int64
+	srv := httptest.NewServer(repoHandler(&hits,
+		func() int { return 0 },
+		time.Now().Add(time.Hour),
+		func() bool { return false }))
+	defer srv.Close()
+
+	a := newTestAnalyzer(t, srv.URL, time.Minute)
+	ctx := context.Background()
+
+	if _, err := a.FetchRepoInfo(ctx, "o", "r"); err != nil {
+		t.Fatalf("first FetchRepoInfo: %v", err)
+	}
+	_, err := a.FetchRepoInfo(ctx, "o", "r")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("second FetchRepoInfo error = %v, want ErrRateLimited", err)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("server saw %d requests, want 1", n)
+	}
+}
+
+// A 403 from GitHub itself is retried by rateLimitTransport after the reset.
+func TestRateLimitTransportRetriesRejection(t *testing.T) {
+	var hits atomic.Int64
+	srv := httptest.NewServer(repoHandler(&hits,
+		func() int {
+			if hits.Load() == 1 {
+				return 0
+			}
+			return 59
+		},
+		time.Now().Add(time.Second),
+		func() bool { return hits.Load() == 1 }))
+	defer srv.Close()
+
+	a := newTestAnalyzer(t, srv.URL, time.Minute)
+	if _, err := a.FetchRepoInfo(context.Background(), "o", "r"); err != nil {
+		t.Fatalf("FetchRepoInfo: %v", err)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("server saw %d requests, want 2", n)
+	}
+	if u := a.Usage(); u.Calls != 2 || u.Remaining != 59 {
+		t.Errorf("Usage() = %+v, want 2 calls and 59 remaining", u)
+	}
+}
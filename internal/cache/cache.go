@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Dir returns the repo-doc cache root: $XDG_CACHE_HOME/repo-doc, falling
+// back to the platform cache directory.
+func Dir() (string, error) {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		var err error
+		base, err = os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate cache directory: %v", err)
+		}
+	}
+	return filepath.Join(base, "repo-doc"), nil
+}
+
+// Store is a directory of cache entries addressed by arbitrary string keys.
+type Store struct {
+	dir string
+}
+
+// Open returns the Store for the named section of the cache, creating it
+// if needed.
+func Open(name string) (*Store, error) {
+	root, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(root, name)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(s.dir, name[:2], name)
+}
+
+// Get returns the entry stored under key.
+func (s *Store) Get(key string) ([]byte, bool) {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Put stores data under key, replacing any previous entry atomically.
+func (s *Store) Put(key string, data []byte) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// SectionStats describes one section of the cache.
+type SectionStats struct {
+	Name    string
+	Entries int
+	Bytes   int64
+}
+
+// Stats reports the entry count and size of every cache section.
+func Stats() (string, []SectionStats, error) {
+	root, err := Dir()
+	if err != nil {
+		return "", nil, err
+	}
+
+	sections, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return root, nil, nil
+	}
+	if err != nil {
+		return root, nil, err
+	}
+
+	var stats []SectionStats
+	for _, section := range sections {
+		if !section.IsDir() {
+			continue
+		}
+		st := SectionStats{Name: section.Name()}
+		err := filepath.WalkDir(filepath.Join(root, section.Name()), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			st.Entries++
+			st.Bytes += info.Size()
+			return nil
+		})
+		if err != nil {
+			return root, nil, err
+		}
+		stats = append(stats, st)
+	}
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return root, stats, nil
+}
+
+// Clear deletes the whole repo-doc cache and returns its location.
+func Clear() (string, error) {
+	root, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return root, os.RemoveAll(root)
+}
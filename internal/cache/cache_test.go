@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	s, err := Open("http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Get("missing"); ok {
+		t.Error("Get of a missing key succeeded")
+	}
+
+	if err := s.Put("key", []byte("one")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put("key", []byte("two")); err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := s.Get("key"); !ok || string(data) != "two" {
+		t.Errorf("Get() = %q, %v; want two", data, ok)
+	}
+	if err := s.Put("other", []byte("three")); err != nil {
+		t.Fatal(err)
+	}
+
+	root, stats, err := Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 || stats[0].Name != "http" || stats[0].Entries != 2 || stats[0].Bytes != 8 {
+		t.Errorf("Stats() = %+v, want 2 http entries of 8 bytes", stats)
+	}
+
+	if _, err := Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(root); !os.IsNotExist(err) {
+		t.Errorf("%s still exists after Clear", root)
+	}
+	if filepath.Base(root) != "repo-doc" {
+		t.Errorf("cache root = %s, want a repo-doc directory", root)
+	}
+}
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+)
+
+// Transport caches GET responses that carry an ETag or Last-Modified header
+// and revalidates them with conditional requests. A 304 reply is turned
+// back into the stored 200 response; GitHub does not count 304s against
+// the rate limit.
+type Transport struct {
+	Base  http.RoundTripper
+	Store *Store
+
+	// Credential, when set, identifies the caller in cache keys instead of
+	// the Authorization header, for credentials whose tokens rotate.
+	Credential string
+}
+
+// fromCacheHeader marks responses served from the cache.
+const fromCacheHeader = "X-From-Cache"
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.Base.RoundTrip(req)
+	}
+
+	key := t.cacheKey(req)
+	cached := t.load(key, req)
+
+	if cached != nil {
+		req = req.Clone(req.Context())
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		// Keep the fresh rate-limit headers from the 304.
+		for name, values := range resp.Header {
+			if strings.HasPrefix(strings.ToLower(name), "x-ratelimit-") {
+				cached.Header[name] = values
+			}
+		}
+		cached.Header.Set(fromCacheHeader, "1")
+		resp.Body.Close()
+		return cached, nil
+	}
+	if cached != nil {
+		cached.Body.Close()
+	}
+
+	if resp.StatusCode == http.StatusOK && (resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "") {
+		t.save(key, resp)
+	}
+
+	return resp, nil
+}
+
+func (t *Transport) load(key string, req *http.Request) *http.Response {
+	data, ok := t.Store.Get(key)
+	if !ok {
+		return nil
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req)
+	if err != nil {
+		return nil
+	}
+	return resp
+}
+
+// save stores resp and replaces its body with an in-memory copy.
+func (t *Transport) save(key string, resp *http.Response) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	stored := *resp
+	stored.Body = io.NopCloser(bytes.NewReader(body))
+	stored.ContentLength = int64(len(body))
+	stored.TransferEncoding = nil
+	stored.Header = resp.Header.Clone()
+	stored.Header.Del("Transfer-Encoding")
+	data, err := httputil.DumpResponse(&stored, true)
+	if err != nil {
+		return
+	}
+	if err := t.Store.Put(key, data); err != nil {
+		log.Printf("Warning: failed to write HTTP cache entry: %v", err)
+	}
+}
+
+// cacheKey separates entries per URL, Accept header and credential, so
+// responses fetched with one token are never served for another.
+func (t *Transport) cacheKey(req *http.Request) string {
+	credential := t.Credential
+	if credential == "" {
+		credential = req.Header.Get("Authorization")
+	}
+	auth := sha256.Sum256([]byte(credential))
+	return strings.Join([]string{
+		req.URL.String(),
+		req.Header.Get("Accept"),
+		hex.EncodeToString(auth[:8]),
+	}, "\n")
+}
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// etagServer serves a fixed body with an ETag and answers matching
+// conditional requests with 304. It counts full and 304 responses.
+func etagServer(full, notModified *atomic.Int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("body for " + r.Header.Get("Authorization")))
+	}))
+}
+
+func get(t *testing.T, client *http.Client, method, url, auth string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", auth)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+func TestTransportRevalidates(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	store, err := Open("http")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var full, notModified atomic.Int64
+	srv := etagServer(&full, &notModified)
+	defer srv.Close()
+	client := &http.Client{Transport: &Transport{Base: http.DefaultTransport, Store: store}}
+
+	resp, body := get(t, client, http.MethodGet, srv.URL+"/repos/o/r", "token a")
+	if resp.Header.Get(fromCacheHeader) != "" || body != "body for token a" {
+		t.Fatalf("first response = %q from cache %q", body, resp.Header.Get(fromCacheHeader))
+	}
+
+	resp, body = get(t, client, http.MethodGet, srv.URL+"/repos/o/r", "token a")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(fromCacheHeader) != "1" || body != "body for token a" {
+		t.Errorf("revalidated response = %d %q from cache %q", resp.StatusCode, body, resp.Header.Get(fromCacheHeader))
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") != "42" {
+		t.Error("rate-limit headers of the 304 were not kept")
+	}
+	if full.Load() != 1 || notModified.Load() != 1 {
+		t.Errorf("server sent %d full and %d 304 responses, want 1 and 1", full.Load(), notModified.Load())
+	}
+}
+
+func TestTransportSeparatesCredentials(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	store, err := Open("http")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var full, notModified atomic.Int64
+	srv := etagServer(&full, &notModified)
+	defer srv.Close()
+	client := &http.Client{Transport: &Transport{Base: http.DefaultTransport, Store: store}}
+
+	get(t, client, http.MethodGet, srv.URL+"/repos/o/r", "token a")
+	_, body := get(t, client, http.MethodGet, srv.URL+"/repos/o/r", "token b")
+	if body != "body for token b" {
+		t.Errorf("second token got %q", body)
+	}
+	if full.Load() != 2 || notModified.Load() != 0 {
+		t.Errorf("server sent %d full and %d 304 responses, want 2 and 0", full.Load(), notModified.Load())
+	}
+}
+
+func TestTransportCredentialOverridesAuthorization(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	store, err := Open("http")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var full, notModified atomic.Int64
+	srv := etagServer(&full, &notModified)
+	defer srv.Close()
+	transport := &Transport{Base: http.DefaultTransport, Store: store, Credential: "app 1 installation 2"}
+	client := &http.Client{Transport: transport}
+
+	// A rotated installation token still revalidates the same entry.
+	get(t, client, http.MethodGet, srv.URL+"/repos/o/r", "token first")
+	resp, body := get(t, client, http.MethodGet, srv.URL+"/repos/o/r", "token second")
+	if resp.Header.Get(fromCacheHeader) != "1" || body != "body for token first" {
+		t.Errorf("second response = %q from cache %q", body, resp.Header.Get(fromCacheHeader))
+	}
+	if full.Load() != 1 || notModified.Load() != 1 {
+		t.Errorf("server sent %d full and %d 304 responses, want 1 and 1", full.Load(), notModified.Load())
+	}
+}
+
+func TestTransportSkipsNonGET(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	store, err := Open("http")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var full, notModified atomic.Int64
+	srv := etagServer(&full, &notModified)
+	defer srv.Close()
+	client := &http.Client{Transport: &Transport{Base: http.DefaultTransport, Store: store}}
+
+	for i := 0; i < 2; i++ {
+		_, body := get(t, client, http.MethodPost, srv.URL+"/graphql", "token a")
+		if !strings.HasPrefix(body, "body for") {
+			t.Errorf("POST got %q", body)
+		}
+	}
+	if full.Load() != 2 {
+		t.Errorf("server sent %d full responses to POSTs, want 2", full.Load())
+	}
+	if _, stats, _ := Stats(); len(stats) != 1 || stats[0].Entries != 0 {
+		t.Errorf("POST responses were cached: %+v", stats)
+	}
+}
@@ -0,0 +1,110 @@
+package gitconfig
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FindGitDir walks up from dir to the nearest repository and returns the
+// directory holding its config. Worktrees and submodules, whose .git is a
+// file pointing elsewhere, are followed to their common git directory.
+func FindGitDir(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		candidate := filepath.Join(dir, ".git")
+		info, err := os.Stat(candidate)
+		if err == nil {
+			if info.IsDir() {
+				return candidate, nil
+			}
+			return resolveGitFile(candidate)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("not inside a git repository")
+		}
+		dir = parent
+	}
+}
+
+// resolveGitFile follows a "gitdir: <path>" file and, for worktrees, the
+// commondir file to the directory holding the shared config.
+func resolveGitFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	line := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return "", fmt.Errorf("unrecognised .git file: %s", path)
+	}
+
+	gitDir := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+
+	if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+		return filepath.Clean(commonDir), nil
+	}
+
+	return filepath.Clean(gitDir), nil
+}
+
+// RemoteURL returns the URL of the named remote for the repository
+// containing dir.
+func RemoteURL(dir, remote string) (string, error) {
+	gitDir, err := FindGitDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(filepath.Join(gitDir, "config"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read git config: %v", err)
+	}
+	defer f.Close()
+
+	wantSection := fmt.Sprintf(`remote "%s"`, remote)
+	inSection := false
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section := strings.TrimSpace(line[1 : len(line)-1])
+			inSection = section == wantSection
+			continue
+		}
+
+		if !inSection {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "url") {
+			return strings.Trim(strings.TrimSpace(value), `"`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read git config: %v", err)
+	}
+
+	return "", fmt.Errorf("remote %q not found in %s", remote, filepath.Join(gitDir, "config"))
+}
@@ -0,0 +1,116 @@
+package gitconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testConfig = `[core]
+	repositoryformatversion = 0
+	bare = false
+; a comment
+[remote "origin"]
+	url = git@github.com:owner/repo.git
+	fetch = +refs/heads/*:refs/remotes/origin/*
+[remote "upstream"]
+	# another comment
+	URL = "https://github.com/upstream/repo"
+[branch "main"]
+	remote = origin
+	url = not-a-remote
+`
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemoteURL(t *testing.T) {
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, ".git", "config"), testConfig)
+	sub := filepath.Join(repo, "cmd", "tool")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		remote  string
+		want    string
+		wantErr bool
+	}{
+		{remote: "origin", want: "git@github.com:owner/repo.git"},
+		{remote: "upstream", want: "https://github.com/upstream/repo"},
+		{remote: "main", wantErr: true},
+		{remote: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			got, err := RemoteURL(sub, tt.remote)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("RemoteURL(%q) = %q, want error", tt.remote, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("RemoteURL(%q) = %q, %v; want %q", tt.remote, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindGitDirFollowsWorktrees(t *testing.T) {
+	root := t.TempDir()
+	main := filepath.Join(root, "main")
+	writeFile(t, filepath.Join(main, ".git", "config"), testConfig)
+
+	// A worktree's .git file points at its private git dir, whose
+	// commondir leads back to the main repository.
+	worktreeGitDir := filepath.Join(main, ".git", "worktrees", "feature")
+	writeFile(t, filepath.Join(worktreeGitDir, "commondir"), "../..\n")
+	worktree := filepath.Join(root, "feature")
+	writeFile(t, filepath.Join(worktree, ".git"), "gitdir: "+worktreeGitDir+"\n")
+
+	got, err := FindGitDir(worktree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(main, ".git"); got != want {
+		t.Errorf("FindGitDir() = %s, want %s", got, want)
+	}
+
+	url, err := RemoteURL(worktree, "origin")
+	if err != nil || url != "git@github.com:owner/repo.git" {
+		t.Errorf("RemoteURL() in worktree = %q, %v", url, err)
+	}
+}
+
+func TestFindGitDirSubmodule(t *testing.T) {
+	root := t.TempDir()
+	modules := filepath.Join(root, ".git", "modules", "lib")
+	writeFile(t, filepath.Join(modules, "config"), testConfig)
+	writeFile(t, filepath.Join(root, "lib", ".git"), "gitdir: ../.git/modules/lib\n")
+
+	got, err := FindGitDir(filepath.Join(root, "lib"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != modules {
+		t.Errorf("FindGitDir() = %s, want %s", got, modules)
+	}
+}
+
+func TestResolveGitFileRejectsGarbage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".git")
+	writeFile(t, path, "not a gitdir line\n")
+	if _, err := resolveGitFile(path); err == nil {
+		t.Error("resolveGitFile accepted a file without gitdir:")
+	}
+}
@@ -0,0 +1,105 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"repo-doc/internal/analyzer"
+)
+
+// CSV and TSV output is one row per record under a fixed header, for
+// spreadsheets and data frames. Timestamps are RFC 3339 in UTC.
+
+var (
+	prColumns       = []string{"number", "title", "state", "author", "merged"}
+	messageColumns  = []string{"pr_number", "pr_title", "kind", "author", "created_at", "review_state", "path", "line", "comment_id", "in_reply_to", "body"}
+	analysisColumns = []string{"pr_number", "author", "kind", "created_at", "sentiment", "score", "content"}
+)
+
+// newDelimitedWriter returns a csv.Writer using commas and CRLF record
+// separators for csv, per RFC 4180, and tabs with LF line endings for tsv.
+// Fields are quoted when needed.
+func (m *Manager) newDelimitedWriter(w io.Writer) *csv.Writer {
+	cw := csv.NewWriter(w)
+	if m.format == "tsv" {
+		cw.Comma = '\t'
+	} else {
+		cw.UseCRLF = true
+	}
+	return cw
+}
+
+func (m *Manager) writePRRows(w io.Writer, prs []*analyzer.PRInfo) error {
+	cw := m.newDelimitedWriter(w)
+	cw.Write(prColumns)
+	for _, pr := range prs {
+		cw.Write([]string{
+			strconv.Itoa(pr.Number),
+			pr.Title,
+			pr.State,
+			pr.Author,
+			strconv.FormatBool(pr.Merged),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (m *Manager) writeMessageRows(w io.Writer, discussions []*analyzer.PRDiscussion) error {
+	cw := m.newDelimitedWriter(w)
+	cw.Write(messageColumns)
+	for _, d := range discussions {
+		for _, msg := range d.Messages {
+			cw.Write([]string{
+				strconv.Itoa(d.PRNumber),
+				d.Title,
+				string(msg.Kind),
+				msg.Author,
+				csvTime(msg.CreatedAt),
+				msg.ReviewState,
+				msg.Path,
+				csvInt(int64(msg.Line)),
+				csvInt(msg.ID),
+				csvInt(msg.InReplyTo),
+				msg.Body,
+			})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (m *Manager) writeAnalysisRows(w io.Writer, report *analyzer.HealthReport) error {
+	cw := m.newDelimitedWriter(w)
+	cw.Write(analysisColumns)
+	for _, msg := range report.Messages {
+		cw.Write([]string{
+			strconv.Itoa(msg.PRNumber),
+			msg.Author,
+			string(msg.Kind),
+			csvTime(msg.CreatedAt),
+			msg.Sentiment,
+			strconv.FormatFloat(msg.Score, 'f', 3, 64),
+			msg.Content,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// csvInt leaves zero values empty so unset fields stay blank.
+func csvInt(n int64) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,251 @@
+package output
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"repo-doc/internal/analyzer"
+)
+
+// The html format renders a single self-contained page: styles are
+// embedded and charts are inline SVG, so the file works offline and can be
+// attached to an email.
+
+//go:embed dashboard.html.tmpl
+var dashboardTemplate string
+
+var dashboardFuncs = template.FuncMap{
+	"time":           func(t time.Time) string { return t.Format(timeLayout) },
+	"reviewVerb":     reviewVerb,
+	"reviewEmoji":    reviewEmoji,
+	"checkEmoji":     checkEmoji,
+	"diffTail":       diffTail,
+	"sentimentEmoji": sentimentEmoji,
+	"prStatusEmoji":  prStatusEmoji,
+	"isDescription":  func(k analyzer.MessageKind) bool { return k == analyzer.KindDescription },
+	"isReview":       func(k analyzer.MessageKind) bool { return k == analyzer.KindReview },
+	"add":            func(a, b int) int { return a + b },
+}
+
+var dashboardPage = template.Must(template.New("dashboard").Funcs(dashboardFuncs).Parse(dashboardTemplate))
+
+// dashboard is everything a page may show; nil sections are left out.
+type dashboard struct {
+	Title      string
+	Generated  string
+	Repo       *analyzer.RepoInfo
+	PRs        []*analyzer.PRInfo
+	States     []stateCount
+	StateTotal int
+	Health     *analyzer.HealthReport
+	Assessment string
+	Pie        []pieSlice
+	Histogram  []histogramBar
+	Detail     *analyzer.PRDetail
+	Threads    []threadView
+}
+
+type stateCount struct {
+	Label string
+	Color string
+	Count int
+	Width float64 // share of the bar, in percent
+}
+
+type pieSlice struct {
+	Label string
+	Color string
+	Count int
+	Pct   float64
+	Path  string // SVG path; empty when the slice is the whole circle
+}
+
+type histogramBar struct {
+	Label  string
+	Count  int
+	X, Y   float64
+	Height float64
+	Color  string
+}
+
+type threadView struct {
+	Anchor   string
+	Number   int
+	Title    string
+	Author   string
+	State    string
+	Merged   bool
+	Messages []analyzer.DiscussionMessage
+	Reviews  []analyzer.ReviewThread
+}
+
+func newDashboard(title string) *dashboard {
+	return &dashboard{
+		Title:     title,
+		Generated: time.Now().Format(timeLayout),
+	}
+}
+
+func (d *dashboard) render(w io.Writer) error {
+	if err := dashboardPage.Execute(w, d); err != nil {
+		return fmt.Errorf("failed to render HTML: %v", err)
+	}
+	return nil
+}
+
+// addStates fills the PR state breakdown.
+func (d *dashboard) addStates(states []string) {
+	counts := map[string]int{}
+	for _, s := range states {
+		counts[s]++
+	}
+
+	d.StateTotal = len(states)
+	for _, s := range []struct{ label, color string }{
+		{"open", "#2da44e"},
+		{"merged", "#8250df"},
+		{"closed", "#cf222e"},
+	} {
+		if counts[s.label] == 0 {
+			continue
+		}
+		d.States = append(d.States, stateCount{
+			Label: s.label,
+			Color: s.color,
+			Count: counts[s.label],
+			Width: float64(counts[s.label]) / float64(len(states)) * 100,
+		})
+	}
+}
+
+// addDiscussions adds a thread section per PR.
+func (d *dashboard) addDiscussions(discussions []*analyzer.PRDiscussion) {
+	for _, disc := range discussions {
+		d.Threads = append(d.Threads, threadView{
+			Anchor:   fmt.Sprintf("pr-%d", disc.PRNumber),
+			Number:   disc.PRNumber,
+			Title:    disc.Title,
+			Author:   disc.Author,
+			State:    prState(disc.State, disc.Merged),
+			Merged:   disc.Merged,
+			Messages: disc.Conversation(),
+			Reviews:  disc.ReviewThreads(),
+		})
+	}
+}
+
+// discussionStates returns the state of each discussed PR.
+func discussionStates(discussions []*analyzer.PRDiscussion) []string {
+	var states []string
+	for _, d := range discussions {
+		states = append(states, prState(d.State, d.Merged))
+	}
+	return states
+}
+
+func (d *dashboard) addHealth(report *analyzer.HealthReport) {
+	d.Health = report
+	if report.MessageCount == 0 {
+		return
+	}
+	d.Assessment = strings.TrimSpace(healthAssessment(report))
+	d.Pie = sentimentPie(report)
+	d.Histogram = scoreHistogram(report)
+}
+
+func prState(state string, merged bool) string {
+	if merged {
+		return "merged"
+	}
+	return strings.ToLower(state)
+}
+
+// pieRadius and pieCenter describe the sentiment pie in its 220x220 viewBox.
+const (
+	pieRadius = 100.0
+	pieCenter = 110.0
+)
+
+func sentimentPie(report *analyzer.HealthReport) []pieSlice {
+	total := float64(report.MessageCount)
+	slices := []pieSlice{
+		{Label: "Positive", Color: "#2da44e", Count: int(report.PositiveScore)},
+		{Label: "Neutral", Color: "#8c959f", Count: int(report.NeutralScore)},
+		{Label: "Negative", Color: "#cf222e", Count: int(report.NegativeScore)},
+	}
+
+	angle := -math.Pi / 2 // start at twelve o'clock
+	for i := range slices {
+		fraction := float64(slices[i].Count) / total
+		slices[i].Pct = fraction * 100
+		if fraction == 0 || fraction == 1 {
+			continue
+		}
+
+		end := angle + fraction*2*math.Pi
+		largeArc := 0
+		if fraction > 0.5 {
+			largeArc = 1
+		}
+		slices[i].Path = fmt.Sprintf("M %.2f %.2f L %.2f %.2f A %.0f %.0f 0 %d 1 %.2f %.2f Z",
+			pieCenter, pieCenter,
+			pieCenter+pieRadius*math.Cos(angle), pieCenter+pieRadius*math.Sin(angle),
+			pieRadius, pieRadius, largeArc,
+			pieCenter+pieRadius*math.Cos(end), pieCenter+pieRadius*math.Sin(end))
+		angle = end
+	}
+	return slices
+}
+
+// histogramBins splits scores from 0 to 1 into equal buckets.
+const histogramBins = 10
+
+// histogramHeight is the tallest bar in the 400x180 histogram viewBox.
+const histogramHeight = 150.0
+
+func scoreHistogram(report *analyzer.HealthReport) []histogramBar {
+	counts := make([]int, histogramBins)
+	for _, msg := range report.Messages {
+		bin := int(msg.Score * histogramBins)
+		if bin >= histogramBins {
+			bin = histogramBins - 1
+		}
+		if bin < 0 {
+			bin = 0
+		}
+		counts[bin]++
+	}
+
+	maxCount := 1
+	for _, c := range counts {
+		if c > maxCount {
+			maxCount = c
+		}
+	}
+
+	bars := make([]histogramBar, histogramBins)
+	for i, c := range counts {
+		height := float64(c) / float64(maxCount) * histogramHeight
+		color := "#8c959f"
+		switch {
+		case i >= 7:
+			color = "#2da44e"
+		case i < 4:
+			color = "#cf222e"
+		}
+		bars[i] = histogramBar{
+			Label:  fmt.Sprintf("%.1f", float64(i)/histogramBins),
+			Count:  c,
+			X:      float64(i) * 40,
+			Y:      histogramHeight - height + 10,
+			Height: height,
+			Color:  color,
+		}
+	}
+	return bars
+}
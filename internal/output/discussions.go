@@ -0,0 +1,158 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"repo-doc/internal/analyzer"
+)
+
+// timeLayout is how message timestamps are shown.
+const timeLayout = "2006-01-02 15:04:05"
+
+// DisplayDiscussions renders PR threads.
+func (m *Manager) DisplayDiscussions(discussions []*analyzer.PRDiscussion) error {
+	switch m.format {
+	case "json":
+		return m.emit(func(w io.Writer) error {
+			return writeJSON(w, struct {
+				Discussions []*analyzer.PRDiscussion `json:"discussions"`
+			}{discussions})
+		})
+	case "markdown":
+		return m.emit(func(w io.Writer) error {
+			writeDiscussionsMarkdown(w, discussions)
+			return nil
+		})
+	case "csv", "tsv":
+		return m.emit(func(w io.Writer) error { return m.writeMessageRows(w, discussions) })
+	case "html":
+		return m.emit(func(w io.Writer) error {
+			d := m.newDashboard("Pull Request Threads")
+			d.addStates(discussionStates(discussions))
+			d.addDiscussions(discussions)
+			return d.render(w)
+		})
+	case "template":
+		return m.executeTemplate(TemplateData{Discussions: discussions})
+	case "table":
+		return m.emit(func(w io.Writer) error {
+			for _, discussion := range discussions {
+				writeDiscussion(w, discussion)
+				fmt.Fprintln(w, "\n"+strings.Repeat("=", 50))
+			}
+			return nil
+		})
+	default:
+		return m.unknownFormat()
+	}
+}
+
+// writeDiscussion writes a PR header followed by its conversation and
+// review threads.
+func writeDiscussion(w io.Writer, discussion *analyzer.PRDiscussion) {
+	header := fmt.Sprintf("%s #%d: %s (👤 %s)", prStatusEmoji(discussion.State, discussion.Merged), discussion.PRNumber, discussion.Title, discussion.Author)
+	fmt.Fprintln(w, "\n"+strings.Repeat("=", len(header)))
+	fmt.Fprintln(w, header)
+	fmt.Fprintln(w, strings.Repeat("=", len(header)))
+
+	writeMessages(w, discussion.Conversation())
+	writeReviewThreads(w, discussion.ReviewThreads())
+}
+
+func writeMessages(w io.Writer, messages []analyzer.DiscussionMessage) {
+	for i, msg := range messages {
+		if i > 0 {
+			fmt.Fprintln(w, "\n"+strings.Repeat("─", 60))
+		}
+		var header string
+		switch msg.Kind {
+		case analyzer.KindDescription:
+			header = fmt.Sprintf("📌 📝 %s (%s)", msg.Author, msg.CreatedAt.Format(timeLayout))
+		case analyzer.KindReview:
+			header = fmt.Sprintf("%s %s %s (%s)", reviewEmoji(msg.ReviewState), msg.Author, reviewVerb(msg.ReviewState), msg.CreatedAt.Format(timeLayout))
+		default:
+			header = fmt.Sprintf("💬 %s (%s)", msg.Author, msg.CreatedAt.Format(timeLayout))
+		}
+
+		fmt.Fprintf(w, "\n%s\n%s\n", header, strings.Repeat("-", len(header)))
+		if msg.Body != "" {
+			fmt.Fprintln(w, msg.Body)
+		}
+	}
+}
+
+// diffContextLines is how much of a review comment's diff hunk is shown;
+// the commented line is the last line of the hunk.
+const diffContextLines = 4
+
+// writeReviewThreads writes review conversations under their file and
+// line, with the end of the diff hunk and indented replies.
+func writeReviewThreads(w io.Writer, threads []analyzer.ReviewThread) {
+	if len(threads) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\n🔍 Review conversations (%d)\n", len(threads))
+	for _, thread := range threads {
+		location := thread.Path
+		if thread.Line > 0 {
+			location = fmt.Sprintf("%s:%d", thread.Path, thread.Line)
+		}
+		fmt.Fprintf(w, "\n📄 %s\n", location)
+
+		if thread.DiffHunk != "" {
+			for _, line := range strings.Split(diffTail(thread.DiffHunk), "\n") {
+				fmt.Fprintf(w, "    │ %s\n", line)
+			}
+		}
+
+		for i, msg := range thread.Comments {
+			indent := "  "
+			if i > 0 {
+				indent = "    ↳ "
+			}
+			fmt.Fprintf(w, "%s💬 %s (%s)\n", indent, msg.Author, msg.CreatedAt.Format(timeLayout))
+
+			bodyIndent := strings.Repeat(" ", len([]rune(indent))+3)
+			for _, line := range strings.Split(msg.Body, "\n") {
+				fmt.Fprintf(w, "%s%s\n", bodyIndent, line)
+			}
+		}
+	}
+}
+
+// diffTail returns the last diffContextLines lines of a diff hunk.
+func diffTail(hunk string) string {
+	lines := strings.Split(strings.TrimRight(hunk, "\n"), "\n")
+	if len(lines) > diffContextLines {
+		lines = lines[len(lines)-diffContextLines:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// reviewVerb describes a review state in a thread header.
+func reviewVerb(state string) string {
+	switch state {
+	case "APPROVED":
+		return "approved"
+	case "CHANGES_REQUESTED":
+		return "requested changes"
+	case "DISMISSED":
+		return "reviewed (dismissed)"
+	default:
+		return "reviewed"
+	}
+}
+
+func prStatusEmoji(state string, merged bool) string {
+	switch {
+	case merged:
+		return "🟣" // Merged PR
+	case strings.EqualFold(state, "closed"):
+		return "🔴" // Closed PR
+	default:
+		return "🟢" // Open PR
+	}
+}
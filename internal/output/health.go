@@ -0,0 +1,131 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"repo-doc/internal/analyzer"
+)
+
+// DisplayHealth renders a PR health report. The analyzed discussions are
+// only included by the html format.
+func (m *Manager) DisplayHealth(report *analyzer.HealthReport, discussions []*analyzer.PRDiscussion) error {
+	switch m.format {
+	case "json":
+		return m.emit(func(w io.Writer) error { return writeJSON(w, report) })
+	case "markdown":
+		return m.emit(func(w io.Writer) error {
+			writeHealthMarkdown(w, report)
+			return nil
+		})
+	case "csv", "tsv":
+		return m.emit(func(w io.Writer) error { return m.writeAnalysisRows(w, report) })
+	case "html":
+		return m.emit(func(w io.Writer) error {
+			d := m.newDashboard("PR Health Report")
+			d.addHealth(report)
+			d.addStates(discussionStates(discussions))
+			d.addDiscussions(discussions)
+			return d.render(w)
+		})
+	case "template":
+		return m.executeTemplate(TemplateData{Health: report, Discussions: discussions})
+	case "table":
+		return m.emit(func(w io.Writer) error {
+			writeHealthReport(w, report)
+			return nil
+		})
+	default:
+		return m.unknownFormat()
+	}
+}
+
+func writeHealthReport(w io.Writer, report *analyzer.HealthReport) {
+	if report.MessageCount == 0 {
+		switch {
+		case report.InterruptedCount > 0:
+			fmt.Fprintf(w, "\n⏹️  Interrupted before any of the %d messages were analyzed.\n", report.FailedCount+report.InterruptedCount)
+		case report.FailedCount > 0:
+			fmt.Fprintf(w, "\n❌ None of the %d messages could be analyzed.\n", report.FailedCount)
+		default:
+			fmt.Fprintln(w, "\n🔍 No messages found to analyze.")
+		}
+		return
+	}
+
+	fmt.Fprintf(w, "\n📊 PR Health Report (%d PRs, %d messages analyzed)\n", report.PRCount, report.MessageCount)
+	fmt.Fprintln(w, strings.Repeat("=", 50))
+	positivePct := 0.0
+	neutralPct := 0.0
+	negativePct := 0.0
+
+	if report.MessageCount > 0 {
+		total := float64(report.MessageCount)
+		positivePct = (float64(report.PositiveScore) / total) * 100
+		neutralPct = (float64(report.NeutralScore) / total) * 100
+		negativePct = (float64(report.NegativeScore) / total) * 100
+	}
+
+	fmt.Fprintf(w, "\n🎭 Sentiment Analysis:")
+	fmt.Fprintf(w, "\n✅ Positive: %.1f%%\n", positivePct)
+	fmt.Fprintf(w, "😐 Neutral:  %.1f%%\n", neutralPct)
+	fmt.Fprintf(w, "❌ Negative: %.1f%%\n", negativePct)
+	fmt.Fprintf(w, "📈 Average Sentiment: %.1f/1.0\n", report.AverageSentiment)
+
+	fmt.Fprintln(w, "\n💬 Sample Messages:")
+	printed := 0
+	for _, msg := range report.Messages {
+		if printed >= 3 {
+			break
+		}
+		emoji := sentimentEmoji(msg.Sentiment)
+		content := msg.Content
+		if len(content) > 100 {
+			content = content[:97] + "..."
+		}
+		fmt.Fprintf(w, "%s [%.1f] %s\n", emoji, msg.Score, content)
+		printed++
+	}
+
+	if report.FailedCount > 0 {
+		fmt.Fprintf(w, "\n⚠️  %d messages could not be analyzed and were excluded\n", report.FailedCount)
+	}
+	if report.InterruptedCount > 0 {
+		fmt.Fprintf(w, "⏹️  %d messages were not analyzed before the run was interrupted\n", report.InterruptedCount)
+	}
+
+	fmt.Fprintln(w, "\n🏥 Health Assessment:")
+	fmt.Fprintln(w, healthAssessment(report))
+
+	fmt.Fprintln(w, strings.Repeat("=", 50))
+}
+
+// healthAssessment sums up a report in one line.
+func healthAssessment(report *analyzer.HealthReport) string {
+	switch {
+	case report.MessageCount == 0:
+		return "ℹ️  No messages to analyze"
+	case report.NegativeScore/float64(report.MessageCount) > 0.5:
+		return "⚠️  Needs attention - High level of negative sentiment"
+	case report.PositiveScore/float64(report.MessageCount) > 0.7:
+		return "🌟 Excellent health - Very positive discussions"
+	case report.AverageSentiment > 0.6:
+		return "👍 Good health - Generally positive discussions"
+	case report.NeutralScore/float64(report.MessageCount) > 0.7:
+		return "➖ Neutral - Mostly technical discussions"
+	default:
+		return "⚠️  Mixed sentiment - Review recommended"
+	}
+}
+
+func sentimentEmoji(sentiment string) string {
+	switch sentiment {
+	case "positive":
+		return "✅"
+	case "negative":
+		return "❌"
+	default:
+		return "➖"
+	}
+}
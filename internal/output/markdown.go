@@ -0,0 +1,294 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"repo-doc/internal/analyzer"
+)
+
+// Markdown output targets GitHub issues and wikis: GFM tables for facts
+// and collapsible <details> sections for long threads.
+
+func writeRepoMarkdown(w io.Writer, info *analyzer.RepoInfo, prs []*analyzer.PRInfo) {
+	fmt.Fprintf(w, "# 📦 %s\n\n", mdText(info.FullName))
+	if info.Description != "" {
+		fmt.Fprintf(w, "> %s\n\n", mdText(info.Description))
+	}
+
+	fmt.Fprintln(w, "| Stat | Value |")
+	fmt.Fprintln(w, "| --- | --- |")
+	fmt.Fprintf(w, "| ⭐ Stars | %d |\n", info.Stars)
+	fmt.Fprintf(w, "| 🍴 Forks | %d |\n", info.Forks)
+	fmt.Fprintf(w, "| 🐛 Open Issues | %d |\n", info.OpenIssues)
+	fmt.Fprintf(w, "| 💻 Language | %s |\n", mdCell(info.Language))
+	fmt.Fprintf(w, "| 📅 Created | %s |\n", info.CreatedAt)
+	fmt.Fprintf(w, "| 🔄 Updated | %s |\n", info.UpdatedAt)
+
+	if len(prs) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\n## 📋 Recent Pull Requests (%d)\n\n", len(prs))
+	fmt.Fprintln(w, "| PR | Title | State | Author |")
+	fmt.Fprintln(w, "| --- | --- | --- | --- |")
+	for _, pr := range prs {
+		state := pr.State
+		if pr.Merged {
+			state = "merged"
+		}
+		fmt.Fprintf(w, "| #%d | %s | %s %s | @%s |\n",
+			pr.Number, mdCell(pr.Title), prStatusEmoji(pr.State, pr.Merged), state, mdCell(pr.Author))
+	}
+}
+
+func writeDiscussionsMarkdown(w io.Writer, discussions []*analyzer.PRDiscussion) {
+	fmt.Fprintf(w, "# 💬 Pull Request Threads (%d)\n", len(discussions))
+	for _, d := range discussions {
+		fmt.Fprintf(w, "\n## %s #%d: %s\n\n", prStatusEmoji(d.State, d.Merged), d.PRNumber, mdText(d.Title))
+		fmt.Fprintf(w, "Opened by @%s\n\n", d.Author)
+		writeThreadMarkdown(w, d.Conversation(), d.ReviewThreads())
+	}
+}
+
+// writeThreadMarkdown writes the conversation and each review thread as
+// collapsible sections.
+func writeThreadMarkdown(w io.Writer, messages []analyzer.DiscussionMessage, threads []analyzer.ReviewThread) {
+	if len(messages) > 0 {
+		fmt.Fprintf(w, "<details>\n<summary>Conversation (%d messages)</summary>\n\n", len(messages))
+		for _, msg := range messages {
+			var header string
+			switch msg.Kind {
+			case analyzer.KindDescription:
+				header = fmt.Sprintf("📝 **@%s** opened", msg.Author)
+			case analyzer.KindReview:
+				header = fmt.Sprintf("%s **@%s** %s", reviewEmoji(msg.ReviewState), msg.Author, reviewVerb(msg.ReviewState))
+			default:
+				header = fmt.Sprintf("💬 **@%s**", msg.Author)
+			}
+			fmt.Fprintf(w, "%s · %s\n\n", header, msg.CreatedAt.Format(timeLayout))
+			if msg.Body != "" {
+				fmt.Fprintf(w, "%s\n\n", mdQuote(escapeAngles(msg.Body)))
+			}
+		}
+		fmt.Fprint(w, "</details>\n\n")
+	}
+
+	for _, thread := range threads {
+		location := thread.Path
+		if thread.Line > 0 {
+			location = fmt.Sprintf("%s:%d", thread.Path, thread.Line)
+		}
+		fmt.Fprintf(w, "<details>\n<summary>🔍 <code>%s</code> (%d comments)</summary>\n\n", htmlEscape(location), len(thread.Comments))
+
+		if thread.DiffHunk != "" {
+			hunk := diffTail(thread.DiffHunk)
+			fence := mdFence(hunk)
+			fmt.Fprintf(w, "%sdiff\n%s\n%s\n\n", fence, hunk, fence)
+		}
+		for i, msg := range thread.Comments {
+			prefix := ""
+			if i > 0 {
+				prefix = "↳ "
+			}
+			fmt.Fprintf(w, "%s💬 **@%s** · %s\n\n", prefix, msg.Author, msg.CreatedAt.Format(timeLayout))
+			fmt.Fprintf(w, "%s\n\n", mdQuote(escapeAngles(msg.Body)))
+		}
+		fmt.Fprint(w, "</details>\n\n")
+	}
+}
+
+func writeHealthMarkdown(w io.Writer, report *analyzer.HealthReport) {
+	fmt.Fprintln(w, "# 📊 PR Health Report")
+	fmt.Fprintln(w)
+
+	if report.MessageCount == 0 {
+		switch {
+		case report.InterruptedCount > 0:
+			fmt.Fprintf(w, "⏹️ Interrupted before any of the %d messages were analyzed.\n", report.FailedCount+report.InterruptedCount)
+		case report.FailedCount > 0:
+			fmt.Fprintf(w, "❌ None of the %d messages could be analyzed.\n", report.FailedCount)
+		default:
+			fmt.Fprintln(w, "🔍 No messages found to analyze.")
+		}
+		return
+	}
+
+	fmt.Fprintf(w, "%d PRs, %d messages analyzed.\n\n", report.PRCount, report.MessageCount)
+	writeSentimentMarkdown(w, report)
+
+	fmt.Fprintf(w, "\n**🏥 Health Assessment:** %s\n", strings.TrimSpace(healthAssessment(report)))
+	if report.FailedCount > 0 {
+		fmt.Fprintf(w, "\n⚠️ %d messages could not be analyzed and were excluded.\n", report.FailedCount)
+	}
+	if report.InterruptedCount > 0 {
+		fmt.Fprintf(w, "\n⏹️ %d messages were not analyzed before the run was interrupted.\n", report.InterruptedCount)
+	}
+
+	fmt.Fprintln(w, "\n<details>\n<summary>💬 Messages</summary>")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Sentiment | Score | Message |")
+	fmt.Fprintln(w, "| --- | --- | --- |")
+	for _, msg := range report.Messages {
+		fmt.Fprintf(w, "| %s %s | %.2f | %s |\n", sentimentEmoji(msg.Sentiment), msg.Sentiment, msg.Score, mdCell(escapeAngles(truncate(msg.Content, 200))))
+	}
+	fmt.Fprintln(w, "\n</details>")
+}
+
+// writeSentimentMarkdown writes the sentiment summary table.
+func writeSentimentMarkdown(w io.Writer, report *analyzer.HealthReport) {
+	total := float64(report.MessageCount)
+	fmt.Fprintln(w, "| Sentiment | Messages | Share |")
+	fmt.Fprintln(w, "| --- | ---: | ---: |")
+	fmt.Fprintf(w, "| ✅ Positive | %.0f | %.1f%% |\n", report.PositiveScore, report.PositiveScore/total*100)
+	fmt.Fprintf(w, "| 😐 Neutral | %.0f | %.1f%% |\n", report.NeutralScore, report.NeutralScore/total*100)
+	fmt.Fprintf(w, "| ❌ Negative | %.0f | %.1f%% |\n", report.NegativeScore, report.NegativeScore/total*100)
+	fmt.Fprintf(w, "| 📈 Average | | %.2f / 1.0 |\n", report.AverageSentiment)
+}
+
+func writePRMarkdown(w io.Writer, d *analyzer.PRDetail, report *analyzer.HealthReport) {
+	fmt.Fprintf(w, "# %s #%d: %s\n\n", prStatusEmoji(d.State, d.Merged), d.Number, mdText(d.Title))
+
+	fmt.Fprintln(w, "| | |")
+	fmt.Fprintln(w, "| --- | --- |")
+	fmt.Fprintf(w, "| 👤 Author | @%s |\n", d.Author)
+	fmt.Fprintf(w, "| 📌 State | %s |\n", prStateLabel(d))
+	if d.HeadRef != "" {
+		fmt.Fprintf(w, "| 🌿 Branch | `%s` → `%s` |\n", d.HeadRef, d.BaseRef)
+	}
+	fmt.Fprintf(w, "| 📅 Created | %s |\n", d.CreatedAt.Format(timeLayout))
+	if d.URL != "" {
+		fmt.Fprintf(w, "| 🔗 URL | %s |\n", d.URL)
+	}
+	fmt.Fprintf(w, "| 🏷️ Labels | %s |\n", mdCell(joinOrNone(d.Labels)))
+	fmt.Fprintf(w, "| 🙋 Assignees | %s |\n", mdCell(joinOrNone(d.Assignees)))
+	fmt.Fprintf(w, "| 👀 Requested | %s |\n", mdCell(joinOrNone(d.RequestedReviewers)))
+
+	fmt.Fprintln(w, "\n## 📝 Description")
+	fmt.Fprintln(w)
+	if d.Body == "" {
+		fmt.Fprintln(w, "_No description provided._")
+	} else {
+		fmt.Fprintln(w, d.Body)
+	}
+
+	fmt.Fprintln(w, "\n## ✅ Reviews")
+	fmt.Fprintln(w)
+	if len(d.Reviews) == 0 {
+		fmt.Fprintln(w, "_No reviews yet._")
+	} else {
+		fmt.Fprintln(w, "| Reviewer | State | Submitted |")
+		fmt.Fprintln(w, "| --- | --- | --- |")
+		for _, r := range d.Reviews {
+			fmt.Fprintf(w, "| @%s | %s %s | %s |\n", r.Reviewer, reviewEmoji(r.State), r.State, r.SubmittedAt.Format(timeLayout))
+		}
+	}
+
+	fmt.Fprintln(w, "\n## 🔧 Checks")
+	fmt.Fprintln(w)
+	if len(d.Checks) == 0 {
+		fmt.Fprintln(w, "_No CI results reported._")
+	} else {
+		fmt.Fprintln(w, "| Check | Result |")
+		fmt.Fprintln(w, "| --- | --- |")
+		for _, c := range d.Checks {
+			name := mdCell(c.Name)
+			if c.URL != "" {
+				name = fmt.Sprintf("[%s](%s)", name, c.URL)
+			}
+			fmt.Fprintf(w, "| %s | %s %s |\n", name, checkEmoji(c.State), c.State)
+		}
+	}
+
+	fmt.Fprintf(w, "\n## 📂 Files changed (%d, +%d −%d)\n\n", len(d.Files), d.Additions, d.Deletions)
+	if len(d.Files) > 0 {
+		fmt.Fprintln(w, "| File | Status | + | − |")
+		fmt.Fprintln(w, "| --- | --- | ---: | ---: |")
+		for _, f := range d.Files {
+			fmt.Fprintf(w, "| `%s` | %s | %d | %d |\n", mdCell(f.Filename), f.Status, f.Additions, f.Deletions)
+		}
+	}
+
+	fmt.Fprintln(w, "\n## 🎭 Sentiment")
+	fmt.Fprintln(w)
+	if report.MessageCount == 0 {
+		fmt.Fprintln(w, "_No messages to analyze._")
+	} else {
+		writeSentimentMarkdown(w, report)
+	}
+	if report.FailedCount > 0 {
+		fmt.Fprintf(w, "\n⚠️ %d messages could not be analyzed and were excluded.\n", report.FailedCount)
+	}
+	if report.InterruptedCount > 0 {
+		fmt.Fprintf(w, "\n⏹️ %d messages were not analyzed before the run was interrupted.\n", report.InterruptedCount)
+	}
+
+	if d.Discussion != nil {
+		var comments []analyzer.DiscussionMessage
+		for _, msg := range d.Discussion.Conversation() {
+			if msg.Kind != analyzer.KindDescription {
+				comments = append(comments, msg)
+			}
+		}
+		fmt.Fprintln(w, "\n## 💬 Discussion")
+		fmt.Fprintln(w)
+		writeThreadMarkdown(w, comments, d.Discussion.ReviewThreads())
+	}
+}
+
+// mdCell makes text safe for a single GFM table cell.
+func mdCell(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// mdText keeps a single line of text from being read as markdown syntax.
+func mdText(s string) string {
+	return strings.NewReplacer("<", "&lt;", ">", "&gt;", "\n", " ").Replace(s)
+}
+
+// mdQuote turns a message body into a blockquote so that its own markdown
+// still renders but stays visually separate from the report.
+func mdQuote(body string) string {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mdFence returns a code fence longer than any run of backticks in s, so
+// the content cannot close the block early.
+func mdFence(s string) string {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r != '`' {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// escapeAngles keeps text placed inside an HTML block such as <details>
+// from opening or closing tags of its own.
+func escapeAngles(s string) string {
+	return strings.NewReplacer("<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func htmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// truncate shortens s to at most n characters.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-3]) + "..."
+}
@@ -0,0 +1,48 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"repo-doc/internal/analyzer"
+)
+
+func TestMdFence(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain text", "```"},
+		{"use `x` here", "```"},
+		{"```go\nfmt.Println()\n```", "````"},
+		{"`````", "``````"},
+	}
+	for _, tt := range tests {
+		if got := mdFence(tt.in); got != tt.want {
+			t.Errorf("mdFence(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteThreadMarkdownKeepsBodiesContained(t *testing.T) {
+	comment := analyzer.DiscussionMessage{Author: "alice", Body: "Try </details><script>alert(1)</script>"}
+	threads := []analyzer.ReviewThread{{
+		Path:     "main.go",
+		Line:     3,
+		DiffHunk: "@@ -1,2 +1,3 @@\n+// ```go\n+// x := 1\n+// ```",
+		Comments: []analyzer.DiscussionMessage{comment},
+	}}
+
+	var b strings.Builder
+	writeThreadMarkdown(&b, []analyzer.DiscussionMessage{comment}, threads)
+	out := b.String()
+
+	if n := strings.Count(out, "</details>"); n != 2 {
+		t.Errorf("got %d </details> tags, want 2 (one per section):\n%s", n, out)
+	}
+	if strings.Contains(out, "<script>") {
+		t.Errorf("message body was not escaped:\n%s", out)
+	}
+	if !strings.Contains(out, "````diff\n") || !strings.Contains(out, "+// ```\n````\n") {
+		t.Errorf("diff hunk fence is not longer than its backtick runs:\n%s", out)
+	}
+}
@@ -1,35 +1,215 @@
 package output
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"repo-doc/internal/analyzer"
 	"strings"
+	"text/template"
 )
 
 type Manager struct {
 	format string
+	path   string
+	force  bool
+
+	// repo is shown by formats that put every report in context.
+	repo *analyzer.RepoInfo
+
+	// tmpl renders the template format.
+	tmpl *template.Template
 }
 
-func New(format, download string) *Manager {
+// New returns a Manager rendering in format. When path is set, reports are
+// written to that file instead of stdout, and an empty format is inferred
+// from its extension. Existing files are only replaced when force is set.
+func New(format, path string, force bool) *Manager {
+	format = strings.ToLower(format)
+	if format == "md" {
+		format = "markdown"
+	}
+	if format == "" {
+		format = FormatFromPath(path)
+	}
+	if format == "" {
+		format = "table"
+	}
+
 	return &Manager{
 		format: format,
+		path:   path,
+		force:  force,
 	}
 }
 
-func (m *Manager) Display(info *analyzer.RepoInfo, prs []*analyzer.PRInfo) error {
+// FormatFromPath returns the format implied by a file extension, or "" if
+// the extension is not recognised.
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json"
+	case ".txt":
+		return "table"
+	case ".md", ".markdown":
+		return "markdown"
+	case ".csv":
+		return "csv"
+	case ".tsv":
+		return "tsv"
+	case ".html", ".htm":
+		return "html"
+	default:
+		return ""
+	}
+}
+
+// Format returns the resolved output format.
+func (m *Manager) Format() string {
+	return m.format
+}
+
+// SetRepository adds repository details to reports about its PRs. Only the
+// html and template formats use them.
+func (m *Manager) SetRepository(info *analyzer.RepoInfo) {
+	m.repo = info
+}
+
+// Path returns the file reports are written to, or "" for stdout.
+func (m *Manager) Path() string {
+	return m.path
+}
 
+// CheckDestination reports early whether the output file can be written,
+// so a long fetch is not wasted on a file that would be refused.
+func (m *Manager) CheckDestination() error {
+	if m.path == "" {
+		return nil
+	}
+
+	if !m.force {
+		if _, err := os.Lstat(m.path); err == nil {
+			return fmt.Errorf("%s already exists; use --force to overwrite it", m.path)
+		}
+	}
+
+	info, err := os.Stat(filepath.Dir(m.path))
+	if err != nil {
+		return fmt.Errorf("cannot write %s: %v", m.path, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("cannot write %s: %s is not a directory", m.path, filepath.Dir(m.path))
+	}
+	return nil
+}
+
+func (m *Manager) Display(info *analyzer.RepoInfo, prs []*analyzer.PRInfo) error {
 	switch m.format {
 	case "json":
-		return m.handleJSON(info, prs)
+		return m.emit(func(w io.Writer) error { return m.handleJSON(w, info, prs) })
 	case "table":
-		return m.handleTable(info, prs)
+		return m.emit(func(w io.Writer) error { return m.handleTable(w, info, prs) })
+	case "markdown":
+		return m.emit(func(w io.Writer) error {
+			writeRepoMarkdown(w, info, prs)
+			return nil
+		})
+	case "csv", "tsv":
+		return m.emit(func(w io.Writer) error { return m.writePRRows(w, prs) })
+	case "html":
+		return m.emit(func(w io.Writer) error {
+			d := newDashboard(info.FullName)
+			d.Repo = info
+			d.PRs = prs
+			var states []string
+			for _, pr := range prs {
+				states = append(states, prState(pr.State, pr.Merged))
+			}
+			d.addStates(states)
+			return d.render(w)
+		})
+	case "template":
+		return m.executeTemplate(TemplateData{Repository: info, PullRequests: prs})
 	default:
-		return fmt.Errorf("unknown format: %s. Use 'table' or 'json'", m.format)
+		return m.unknownFormat()
+	}
+}
+
+// newDashboard starts an html page, titled after the repository when known.
+func (m *Manager) newDashboard(title string) *dashboard {
+	if m.repo != nil {
+		title = m.repo.FullName + " · " + title
+	}
+	d := newDashboard(title)
+	d.Repo = m.repo
+	return d
+}
+
+func (m *Manager) unknownFormat() error {
+	return fmt.Errorf("unknown format: %s. Use 'table', 'json', 'markdown', 'csv', 'tsv', 'html' or 'template'", m.format)
+}
+
+// emit runs render against stdout, or against a buffer that is then
+// written atomically to the output file.
+func (m *Manager) emit(render func(w io.Writer) error) error {
+	if m.path == "" {
+		return render(os.Stdout)
+	}
+
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		return err
 	}
+	return m.writeFile(buf.Bytes())
 }
 
-func (m *Manager) handleJSON(info *analyzer.RepoInfo, prs []*analyzer.PRInfo) error {
+// writeFile writes data to a temporary file next to the destination and
+// renames it into place, so readers never see a partial report.
+func (m *Manager) writeFile(data []byte) error {
+	if err := m.CheckDestination(); err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(m.path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(m.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", m.path, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %v", m.path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %v", m.path, err)
+	}
+	// CreateTemp uses 0600; reports are ordinary files.
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %v", m.path, err)
+	}
+	if err := os.Rename(tmp.Name(), m.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %v", m.path, err)
+	}
+	return nil
+}
+
+// writeJSON writes v as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	jsonData, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %v", err)
+	}
+	_, err = fmt.Fprintln(w, string(jsonData))
+	return err
+}
+
+func (m *Manager) handleJSON(w io.Writer, info *analyzer.RepoInfo, prs []*analyzer.PRInfo) error {
 	data := struct {
 		Repository   *analyzer.RepoInfo `json:"repository"`
 		PullRequests []*analyzer.PRInfo `json:"pull_requests"`
@@ -38,23 +218,15 @@ func (m *Manager) handleJSON(info *analyzer.RepoInfo, prs []*analyzer.PRInfo) er
 		PullRequests: prs,
 	}
 
-	jsonData, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %v", err)
-	}
-	if m.format == "json" {
-		fmt.Println(string(jsonData))
-	}
-
-	return nil
+	return writeJSON(w, data)
 }
 
-func (m *Manager) handleTable(info *analyzer.RepoInfo, prs []*analyzer.PRInfo) error {
+func (m *Manager) handleTable(w io.Writer, info *analyzer.RepoInfo, prs []*analyzer.PRInfo) error {
 
 	output := m.formatTable(info, prs)
-	fmt.Print(output)
+	_, err := fmt.Fprint(w, output)
 
-	return nil
+	return err
 }
 
 func (m *Manager) formatTable(info *analyzer.RepoInfo, prs []*analyzer.PRInfo) string {
@@ -0,0 +1,187 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"repo-doc/internal/analyzer"
+)
+
+// DisplayPR renders a single PR in full together with the sentiment of its
+// discussion.
+func (m *Manager) DisplayPR(detail *analyzer.PRDetail, report *analyzer.HealthReport) error {
+	switch m.format {
+	case "json":
+		return m.emit(func(w io.Writer) error {
+			return writeJSON(w, struct {
+				PullRequest *analyzer.PRDetail     `json:"pull_request"`
+				Health      *analyzer.HealthReport `json:"health"`
+			}{detail, report})
+		})
+	case "markdown":
+		return m.emit(func(w io.Writer) error {
+			writePRMarkdown(w, detail, report)
+			return nil
+		})
+	case "csv", "tsv":
+		return m.emit(func(w io.Writer) error {
+			return m.writeMessageRows(w, []*analyzer.PRDiscussion{detail.Discussion})
+		})
+	case "html":
+		return m.emit(func(w io.Writer) error {
+			page := m.newDashboard(fmt.Sprintf("PR #%d", detail.Number))
+			page.Detail = detail
+			page.addHealth(report)
+			if detail.Discussion != nil {
+				page.addDiscussions([]*analyzer.PRDiscussion{detail.Discussion})
+			}
+			return page.render(w)
+		})
+	case "template":
+		var discussions []*analyzer.PRDiscussion
+		if detail.Discussion != nil {
+			discussions = []*analyzer.PRDiscussion{detail.Discussion}
+		}
+		return m.executeTemplate(TemplateData{PullRequest: detail, Health: report, Discussions: discussions})
+	case "table":
+		return m.emit(func(w io.Writer) error {
+			writePRDetail(w, detail, report)
+			return nil
+		})
+	default:
+		return m.unknownFormat()
+	}
+}
+
+func writePRDetail(w io.Writer, d *analyzer.PRDetail, report *analyzer.HealthReport) {
+	header := fmt.Sprintf("%s #%d: %s", prStatusEmoji(d.State, d.Merged), d.Number, d.Title)
+	fmt.Fprintln(w, strings.Repeat("=", 80))
+	fmt.Fprintln(w, header)
+	fmt.Fprintln(w, strings.Repeat("=", 80))
+
+	fmt.Fprintf(w, "👤 Author:    %s\n", d.Author)
+	fmt.Fprintf(w, "📌 State:     %s\n", prStateLabel(d))
+	if d.HeadRef != "" {
+		fmt.Fprintf(w, "🌿 Branch:    %s → %s\n", d.HeadRef, d.BaseRef)
+	}
+	fmt.Fprintf(w, "📅 Created:   %s\n", d.CreatedAt.Format(timeLayout))
+	if d.URL != "" {
+		fmt.Fprintf(w, "🔗 URL:       %s\n", d.URL)
+	}
+	fmt.Fprintf(w, "🏷️  Labels:    %s\n", joinOrNone(d.Labels))
+	fmt.Fprintf(w, "🙋 Assignees: %s\n", joinOrNone(d.Assignees))
+	fmt.Fprintf(w, "👀 Requested: %s\n", joinOrNone(d.RequestedReviewers))
+
+	fmt.Fprintln(w, "\n📝 Description")
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	if d.Body == "" {
+		fmt.Fprintln(w, "No description provided.")
+	} else {
+		fmt.Fprintln(w, d.Body)
+	}
+
+	fmt.Fprintln(w, "\n✅ Reviews")
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	if len(d.Reviews) == 0 {
+		fmt.Fprintln(w, "No reviews yet.")
+	}
+	for _, r := range d.Reviews {
+		fmt.Fprintf(w, "%s %-20s %-18s %s\n", reviewEmoji(r.State), r.Reviewer, r.State, r.SubmittedAt.Format(timeLayout))
+	}
+
+	fmt.Fprintln(w, "\n🔧 Checks")
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	if len(d.Checks) == 0 {
+		fmt.Fprintln(w, "No CI results reported.")
+	}
+	for _, c := range d.Checks {
+		fmt.Fprintf(w, "%s %-40s %s\n", checkEmoji(c.State), c.Name, c.State)
+	}
+
+	fmt.Fprintf(w, "\n📂 Files changed (%d, +%d -%d)\n", len(d.Files), d.Additions, d.Deletions)
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	for _, f := range d.Files {
+		fmt.Fprintf(w, "+%-6d -%-6d %s (%s)\n", f.Additions, f.Deletions, f.Filename, f.Status)
+	}
+
+	fmt.Fprintln(w, "\n🎭 Sentiment")
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	if report.MessageCount == 0 {
+		fmt.Fprintln(w, "No messages to analyze.")
+	} else {
+		total := float64(report.MessageCount)
+		fmt.Fprintf(w, "✅ Positive: %.0f (%.1f%%)\n", report.PositiveScore, report.PositiveScore/total*100)
+		fmt.Fprintf(w, "😐 Neutral:  %.0f (%.1f%%)\n", report.NeutralScore, report.NeutralScore/total*100)
+		fmt.Fprintf(w, "❌ Negative: %.0f (%.1f%%)\n", report.NegativeScore, report.NegativeScore/total*100)
+		fmt.Fprintf(w, "📈 Average Sentiment: %.1f/1.0\n", report.AverageSentiment)
+	}
+	if report.FailedCount > 0 {
+		fmt.Fprintf(w, "⚠️  %d messages could not be analyzed and were excluded\n", report.FailedCount)
+	}
+	if report.InterruptedCount > 0 {
+		fmt.Fprintf(w, "⏹️  %d messages were not analyzed before the run was interrupted\n", report.InterruptedCount)
+	}
+
+	if d.Discussion != nil {
+		// The description is already shown above.
+		var comments []analyzer.DiscussionMessage
+		for _, msg := range d.Discussion.Conversation() {
+			if msg.Kind != analyzer.KindDescription {
+				comments = append(comments, msg)
+			}
+		}
+		if len(comments) > 0 {
+			fmt.Fprintf(w, "\n💬 Discussion (%d messages)\n", len(comments))
+			fmt.Fprintln(w, strings.Repeat("=", 80))
+			writeMessages(w, comments)
+		}
+		writeReviewThreads(w, d.Discussion.ReviewThreads())
+	}
+	fmt.Fprintln(w, strings.Repeat("=", 80))
+}
+
+// prStateLabel is a PR's state with merges and drafts spelled out.
+func prStateLabel(d *analyzer.PRDetail) string {
+	state := d.State
+	if d.Merged {
+		state = "merged"
+	}
+	if d.Draft {
+		state += " (draft)"
+	}
+	return state
+}
+
+func joinOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
+
+func reviewEmoji(state string) string {
+	switch state {
+	case "APPROVED":
+		return "✅"
+	case "CHANGES_REQUESTED":
+		return "❌"
+	case "DISMISSED":
+		return "🚫"
+	default:
+		return "💬"
+	}
+}
+
+func checkEmoji(state string) string {
+	switch state {
+	case "success", "neutral", "skipped":
+		return "✅"
+	case "failure", "error", "timed_out", "action_required", "startup_failure":
+		return "❌"
+	case "cancelled", "stale":
+		return "⚪"
+	default:
+		return "⏳"
+	}
+}
@@ -0,0 +1,163 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"repo-doc/internal/analyzer"
+)
+
+// TemplateData is what user templates are executed with. Fields that the
+// running command does not produce are nil.
+type TemplateData struct {
+	Repository   *analyzer.RepoInfo
+	PullRequests []*analyzer.PRInfo
+	Discussions  []*analyzer.PRDiscussion
+	Health       *analyzer.HealthReport
+	PullRequest  *analyzer.PRDetail
+	Generated    time.Time
+}
+
+// TemplateFuncs are the helpers available to user templates.
+var TemplateFuncs = template.FuncMap{
+	"truncate":   templateTruncate,
+	"date":       templateDate,
+	"percent":    templatePercent,
+	"stateEmoji": templateStateEmoji,
+	"join":       strings.Join,
+}
+
+// LoadTemplate parses the text/template at path for the template format.
+func (m *Manager) LoadTemplate(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %v", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(TemplateFuncs).Parse(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid template: %v", err)
+	}
+	m.tmpl = tmpl
+	return nil
+}
+
+// executeTemplate renders data through the loaded template. The template is
+// executed into a buffer first, so a failing template writes nothing, not
+// even to stdout.
+func (m *Manager) executeTemplate(data TemplateData) error {
+	if m.tmpl == nil {
+		return fmt.Errorf("the template format needs a template file (--template)")
+	}
+
+	data.Generated = time.Now()
+	if data.Repository == nil {
+		data.Repository = m.repo
+	}
+
+	var buf bytes.Buffer
+	if err := m.tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to execute template: %v", err)
+	}
+	return m.emit(func(w io.Writer) error {
+		_, err := buf.WriteTo(w)
+		return err
+	})
+}
+
+// templateTruncate shortens s to n characters: {{.Title | truncate 40}}.
+func templateTruncate(n int, s string) string {
+	runes := []rune(s)
+	switch {
+	case len(runes) <= n:
+		return s
+	case n < 4:
+		return string(runes[:max(n, 0)])
+	default:
+		return truncate(s, n)
+	}
+}
+
+// templateDate formats a time with a Go layout: {{date "Jan 2" .CreatedAt}}.
+// Dates already rendered as 2006-01-02, as in RepoInfo, are accepted too.
+// Zero times format as an empty string.
+func templateDate(layout string, v any) (string, error) {
+	var t time.Time
+	switch value := v.(type) {
+	case time.Time:
+		t = value
+	case string:
+		if value == "" {
+			return "", nil
+		}
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return "", fmt.Errorf("date: %v", err)
+		}
+		t = parsed
+	default:
+		return "", fmt.Errorf("date: %v is not a time", v)
+	}
+
+	if t.IsZero() {
+		return "", nil
+	}
+	return t.Format(layout), nil
+}
+
+// templatePercent formats part/total as a percentage:
+// {{percent .Health.PositiveScore .Health.MessageCount}}.
+func templatePercent(part, total any) (string, error) {
+	p, err := toFloat(part)
+	if err != nil {
+		return "", err
+	}
+	t, err := toFloat(total)
+	if err != nil {
+		return "", err
+	}
+	if t == 0 {
+		return "0.0%", nil
+	}
+	return fmt.Sprintf("%.1f%%", p/t*100), nil
+}
+
+func toFloat(v any) (float64, error) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	default:
+		return 0, fmt.Errorf("percent: %v is not a number", v)
+	}
+}
+
+// templateStateEmoji returns the emoji for a PR state ({{stateEmoji .State
+// .Merged}}), a review state, a CI check state or a sentiment label.
+func templateStateEmoji(state string, merged ...bool) string {
+	if len(merged) > 0 && merged[0] {
+		return prStatusEmoji(state, true)
+	}
+
+	switch strings.ToLower(state) {
+	case "open", "closed":
+		return prStatusEmoji(state, false)
+	case "merged":
+		return prStatusEmoji(state, true)
+	case "approved", "changes_requested", "dismissed", "commented":
+		return reviewEmoji(strings.ToUpper(state))
+	case "positive", "negative", "neutral":
+		return sentimentEmoji(strings.ToLower(state))
+	default:
+		return checkEmoji(strings.ToLower(state))
+	}
+}
@@ -0,0 +1,110 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultRetries is how many times a transient failure is retried.
+	DefaultRetries = 3
+
+	baseDelay = 500 * time.Millisecond
+	maxDelay  = 30 * time.Second
+)
+
+// Backoff returns the delay before retry number attempt (starting at 0):
+// exponential growth capped at maxDelay, with full jitter so concurrent
+// workers do not retry in lockstep.
+func Backoff(attempt int) time.Duration {
+	d := baseDelay << attempt
+	if d <= 0 || d > maxDelay {
+		d = maxDelay
+	}
+	return time.Duration(rand.Int63n(int64(d))) + time.Millisecond
+}
+
+// Sleep waits for d or until ctx is done.
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// TransientStatus reports whether an HTTP status is worth retrying.
+func TransientStatus(code int) bool {
+	switch code {
+	case http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// httpCoder is implemented by API errors that carry an HTTP status, such as
+// Google's apierror.APIError.
+type httpCoder interface {
+	HTTPCode() int
+}
+
+// Transient reports whether err is a timeout, reset connection or 5xx
+// response that may succeed when retried. Cancellation is never transient.
+func Transient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var coder httpCoder
+	if errors.As(err, &coder) && coder.HTTPCode() > 0 {
+		return TransientStatus(coder.HTTPCode()) || coder.HTTPCode() == http.StatusTooManyRequests
+	}
+
+	// Google API clients report gRPC status codes.
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Aborted:
+			return true
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// Do calls fn until it succeeds, fails with a non-transient error, or
+// retries attempts have been used up.
+func Do(ctx context.Context, retries int, fn func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= retries || !Transient(err) || ctx.Err() != nil {
+			return err
+		}
+		if sleepErr := Sleep(ctx, Backoff(attempt)); sleepErr != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type codeError int
+
+func (e codeError) Error() string { return fmt.Sprintf("HTTP %d", int(e)) }
+func (e codeError) HTTPCode() int { return int(e) }
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain error", errors.New("invalid model"), false},
+		{"canceled", context.Canceled, false},
+		{"wrapped canceled", fmt.Errorf("request: %w", context.Canceled), false},
+		{"deadline", context.DeadlineExceeded, true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"connection reset", &url.Error{Op: "Get", URL: "https://api.github.com", Err: syscall.ECONNRESET}, true},
+		{"connection refused", fmt.Errorf("dial: %w", syscall.ECONNREFUSED), true},
+		{"network timeout", &url.Error{Op: "Get", URL: "https://api.github.com", Err: timeoutError{}}, true},
+		{"http 503", codeError(503), true},
+		{"http 429", codeError(429), true},
+		{"http 400", codeError(400), false},
+		{"http 404", codeError(404), false},
+		{"grpc unavailable", status.Error(codes.Unavailable, "down"), true},
+		{"grpc resource exhausted", status.Error(codes.ResourceExhausted, "quota"), true},
+		{"grpc invalid argument", status.Error(codes.InvalidArgument, "bad request"), false},
+		{"grpc permission denied", status.Error(codes.PermissionDenied, "bad key"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Transient(tt.err); got != tt.want {
+				t.Errorf("Transient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransientStatus(t *testing.T) {
+	for code, want := range map[int]bool{200: false, 304: false, 403: false, 429: false, 500: true, 502: true, 503: true, 504: true} {
+		if got := TransientStatus(code); got != want {
+			t.Errorf("TransientStatus(%d) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 70; attempt++ {
+		d := Backoff(attempt)
+		limit := maxDelay
+		if attempt < 10 {
+			limit = min(baseDelay<<attempt, maxDelay)
+		}
+		if d <= 0 || d > limit+time.Millisecond {
+			t.Errorf("Backoff(%d) = %v, want within (0, %v]", attempt, d, limit+time.Millisecond)
+		}
+	}
+}
+
+func TestDo(t *testing.T) {
+	ctx := context.Background()
+
+	calls := 0
+	err := Do(ctx, 3, func() error {
+		calls++
+		if calls < 2 {
+			return codeError(503)
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("transient failure then success: err = %v after %d calls, want nil after 2", err, calls)
+	}
+
+	calls = 0
+	err = Do(ctx, 3, func() error {
+		calls++
+		return codeError(400)
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("permanent failure: err = %v after %d calls, want error after 1", err, calls)
+	}
+
+	calls = 0
+	err = Do(ctx, 1, func() error {
+		calls++
+		return codeError(503)
+	})
+	if err == nil || calls != 2 {
+		t.Errorf("exhausted retries: err = %v after %d calls, want error after 2", err, calls)
+	}
+}
+
+func TestSleepCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	if err := Sleep(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("Sleep() = %v, want context.Canceled", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Sleep did not return when ctx was cancelled")
+	}
+}
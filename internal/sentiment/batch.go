@@ -0,0 +1,178 @@
+package sentiment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// DefaultBatchSize is the number of messages packed into one LLM prompt.
+const DefaultBatchSize = 20
+
+// maxBatchTextLen caps each message in a batch prompt so one huge comment
+// cannot crowd out the rest of the batch.
+const maxBatchTextLen = 2000
+
+// Item is one message submitted for classification.
+type Item struct {
+	ID   string
+	Text string
+}
+
+// BatchResult is the classification of one Item in a batch reply.
+type BatchResult struct {
+	ID        string  `json:"id"`
+	Sentiment string  `json:"sentiment"`
+	Score     float64 `json:"score"`
+}
+
+// Batcher is implemented by backends that can classify several messages
+// in a single request.
+type Batcher interface {
+	AnalyzeBatch(ctx context.Context, items []Item) ([]BatchResult, error)
+}
+
+// AnalyzeAll classifies items and returns the results keyed by Item.ID.
+// Backends implementing Batcher receive items in batches of batchSize;
+// anything missing or invalid in a batch reply is retried one message at
+// a time. Items that still fail are logged and left out of the result.
+//
+// Items not yet classified when ctx is cancelled are left out too and
+// counted in interrupted, so callers can tell them from failures. The
+// lexicon backend runs locally and quickly, so it ignores cancellation and
+// always classifies every item.
+func AnalyzeAll(ctx context.Context, s Sentiment, items []Item, batchSize int) (results map[string]*SentimentResponse, interrupted int) {
+	results = make(map[string]*SentimentResponse, len(items))
+	if batchSize < 1 {
+		batchSize = DefaultBatchSize
+	}
+	if _, ok := s.(*Lexicon); ok {
+		ctx = context.WithoutCancel(ctx)
+	}
+
+	var pending []Item
+	for _, item := range items {
+		if CleanText(item.Text) == "" {
+			results[item.ID] = &SentimentResponse{Sentiment: "neutral", Score: 0.5}
+			continue
+		}
+		pending = append(pending, item)
+	}
+
+	if b, ok := s.(Batcher); ok && batchSize > 1 {
+		var leftover []Item
+		for start := 0; start < len(pending); start += batchSize {
+			if ctx.Err() != nil {
+				return results, len(leftover) + len(pending) - start
+			}
+			end := start + batchSize
+			if end > len(pending) {
+				end = len(pending)
+			}
+			batch := pending[start:end]
+
+			batchCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+			batchResults, err := b.AnalyzeBatch(batchCtx, batch)
+			cancel()
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Batch of %d messages failed with %s, falling back to per-message analysis: %v", len(batch), s.Name(), err)
+			}
+			for _, r := range batchResults {
+				results[r.ID] = &SentimentResponse{Sentiment: r.Sentiment, Score: r.Score}
+			}
+			for _, item := range batch {
+				if _, ok := results[item.ID]; !ok {
+					leftover = append(leftover, item)
+				}
+			}
+		}
+		pending = leftover
+	}
+
+	for i, item := range pending {
+		if ctx.Err() != nil {
+			return results, len(pending) - i
+		}
+		msgCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		result, err := s.Analyze(msgCtx, item.Text)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return results, len(pending) - i
+			}
+			log.Printf("Error analyzing message with %s: %v", s.Name(), err)
+			continue
+		}
+		results[item.ID] = result
+	}
+
+	return results, 0
+}
+
+// buildBatchPrompt returns a prompt asking for one classification per item.
+func buildBatchPrompt(items []Item) (string, error) {
+	type promptItem struct {
+		ID   string `json:"id"`
+		Text string `json:"text"`
+	}
+
+	payload := make([]promptItem, 0, len(items))
+	for _, item := range items {
+		text := CleanText(item.Text)
+		if len(text) > maxBatchTextLen {
+			text = text[:maxBatchTextLen]
+		}
+		payload = append(payload, promptItem{ID: item.ID, Text: text})
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal batch: %v", err)
+	}
+
+	return fmt.Sprintf(`Analyze the sentiment of each GitHub PR discussion message in the JSON array below. Respond with a JSON array containing one object per message with "id" (copied from the input), "sentiment" (one of: "positive", "neutral", "negative") and "score" (0.0 to 1.0, where 0 is most negative and 1 is most positive).
+
+Messages to analyze:
+%s
+
+Respond with only the JSON array, nothing else.`, string(data)), nil
+}
+
+// parseBatchResponse extracts the JSON array in an LLM batch reply. Entries
+// with unknown IDs or invalid values are dropped so the caller can retry
+// those messages individually.
+func parseBatchResponse(responseText string, items []Item) ([]BatchResult, error) {
+	jsonStart := strings.Index(responseText, "[")
+	jsonEnd := strings.LastIndex(responseText, "]")
+	if jsonStart == -1 || jsonEnd == -1 || jsonEnd < jsonStart {
+		return nil, fmt.Errorf("invalid JSON array response: %s", responseText)
+	}
+
+	var raw []BatchResult
+	if err := json.Unmarshal([]byte(responseText[jsonStart:jsonEnd+1]), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON array response: %v", err)
+	}
+
+	known := make(map[string]bool, len(items))
+	for _, item := range items {
+		known[item.ID] = true
+	}
+
+	results := make([]BatchResult, 0, len(raw))
+	for _, r := range raw {
+		if !known[r.ID] {
+			continue
+		}
+		if err := validate(&SentimentResponse{Sentiment: r.Sentiment, Score: r.Score}); err != nil {
+			log.Printf("Dropping batch result for %s: %v", r.ID, err)
+			continue
+		}
+		known[r.ID] = false
+		results = append(results, r)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,247 @@
+package sentiment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeBatcher classifies texts starting with "good" as positive and
+// everything else as negative. Texts listed in skip are left out of batch
+// replies, and texts in fail also fail individually.
+type fakeBatcher struct {
+	skip map[string]bool
+	fail map[string]bool
+
+	mu       sync.Mutex
+	batches  [][]Item
+	analyzed []string
+}
+
+func (f *fakeBatcher) Name() string  { return "fake" }
+func (f *fakeBatcher) Model() string { return "" }
+func (f *fakeBatcher) Close() error  { return nil }
+
+func (f *fakeBatcher) classify(text string) *SentimentResponse {
+	if strings.HasPrefix(text, "good") {
+		return &SentimentResponse{Sentiment: "positive", Score: 0.9}
+	}
+	return &SentimentResponse{Sentiment: "negative", Score: 0.1}
+}
+
+func (f *fakeBatcher) Analyze(ctx context.Context, text string) (*SentimentResponse, error) {
+	f.mu.Lock()
+	f.analyzed = append(f.analyzed, text)
+	f.mu.Unlock()
+
+	if f.fail[text] {
+		return nil, errors.New("backend failure")
+	}
+	return f.classify(text), nil
+}
+
+func (f *fakeBatcher) AnalyzeBatch(ctx context.Context, items []Item) ([]BatchResult, error) {
+	f.mu.Lock()
+	f.batches = append(f.batches, items)
+	f.mu.Unlock()
+
+	var results []BatchResult
+	for _, item := range items {
+		if f.skip[item.Text] || f.fail[item.Text] {
+			continue
+		}
+		r := f.classify(item.Text)
+		results = append(results, BatchResult{ID: item.ID, Sentiment: r.Sentiment, Score: r.Score})
+	}
+	return results, nil
+}
+
+func TestAnalyzeAllMapsResultsToIDs(t *testing.T) {
+	f := &fakeBatcher{
+		skip: map[string]bool{"good but skipped": true},
+		fail: map[string]bool{"bad and failing": true},
+	}
+	items := []Item{
+		{ID: "1", Text: "good change"},
+		{ID: "2", Text: "bad change"},
+		{ID: "3", Text: "   "},
+		{ID: "4", Text: "good but skipped"},
+		{ID: "5", Text: "bad and failing"},
+	}
+
+	results, interrupted := AnalyzeAll(context.Background(), f, items, 2)
+	if interrupted != 0 {
+		t.Errorf("interrupted = %d, want 0", interrupted)
+	}
+
+	want := map[string]string{"1": "positive", "2": "negative", "3": "neutral", "4": "positive"}
+	if len(results) != len(want) {
+		t.Errorf("got %d results, want %d", len(results), len(want))
+	}
+	for id, sentiment := range want {
+		if r, ok := results[id]; !ok || r.Sentiment != sentiment {
+			t.Errorf("result for %s = %+v, want %s", id, r, sentiment)
+		}
+	}
+	if _, ok := results["5"]; ok {
+		t.Error("failed message 5 has a result")
+	}
+
+	// Blank messages are never sent; the rest go out in batches of 2.
+	if len(f.batches) != 2 || len(f.batches[0]) != 2 || len(f.batches[1]) != 2 {
+		t.Errorf("batches = %v, want two batches of 2", f.batches)
+	}
+	// Only messages missing from the batch replies are retried one by one.
+	if fmt.Sprint(f.analyzed) != "[good but skipped bad and failing]" {
+		t.Errorf("analyzed individually = %q", f.analyzed)
+	}
+}
+
+func TestAnalyzeAllWithoutBatching(t *testing.T) {
+	f := &fakeBatcher{}
+	items := []Item{{ID: "a", Text: "good"}, {ID: "b", Text: "bad"}}
+
+	results, _ := AnalyzeAll(context.Background(), f, items, 1)
+
+	if len(f.batches) != 0 {
+		t.Errorf("batch size 1 sent %d batches", len(f.batches))
+	}
+	if results["a"].Sentiment != "positive" || results["b"].Sentiment != "negative" {
+		t.Errorf("results = %v", results)
+	}
+}
+
+func TestAnalyzeAllCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	items := []Item{{ID: "a", Text: "good"}, {ID: "b", Text: "bad"}, {ID: "c", Text: ""}}
+
+	// The lexicon runs locally, so it finishes regardless.
+	results, interrupted := AnalyzeAll(ctx, NewLexicon(), items, DefaultBatchSize)
+	if len(results) != 3 || interrupted != 0 {
+		t.Errorf("lexicon: %d results, %d interrupted; want 3 and 0", len(results), interrupted)
+	}
+
+	// Other backends stop, and report what they skipped as interrupted.
+	f := &fakeBatcher{}
+	results, interrupted = AnalyzeAll(ctx, f, items, 1)
+	if len(results) != 1 || interrupted != 2 {
+		t.Errorf("backend: %d results, %d interrupted; want 1 and 2", len(results), interrupted)
+	}
+	if len(f.analyzed) != 0 {
+		t.Errorf("cancelled run analyzed %q", f.analyzed)
+	}
+}
+
+// cancellingBatcher cancels the run once its first batch is answered.
+type cancellingBatcher struct {
+	fakeBatcher
+	cancel context.CancelFunc
+}
+
+func (c *cancellingBatcher) AnalyzeBatch(ctx context.Context, items []Item) ([]BatchResult, error) {
+	defer c.cancel()
+	return c.fakeBatcher.AnalyzeBatch(ctx, items)
+}
+
+func TestAnalyzeAllCancelledBetweenBatches(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := &cancellingBatcher{cancel: cancel}
+
+	var items []Item
+	for i := 0; i < 50; i++ {
+		items = append(items, Item{ID: fmt.Sprint(i), Text: "good"})
+	}
+
+	results, interrupted := AnalyzeAll(ctx, b, items, 20)
+	if len(results) != 20 || interrupted != 30 {
+		t.Errorf("%d results, %d interrupted; want 20 and 30", len(results), interrupted)
+	}
+	if len(b.batches) != 1 {
+		t.Errorf("sent %d batches after cancellation, want 1", len(b.batches))
+	}
+}
+
+func TestParseBatchResponse(t *testing.T) {
+	items := []Item{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+
+	tests := []struct {
+		name    string
+		reply   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "plain array",
+			reply: `[{"id":"1","sentiment":"positive","score":0.9},{"id":"2","sentiment":"negative","score":0.2}]`,
+			want:  []string{"1:positive", "2:negative"},
+		},
+		{
+			name:  "wrapped in prose and a code fence",
+			reply: "Here you go:\n```json\n[{\"id\":\"3\",\"sentiment\":\"neutral\",\"score\":0.5}]\n```",
+			want:  []string{"3:neutral"},
+		},
+		{
+			name:  "unknown ids dropped",
+			reply: `[{"id":"9","sentiment":"positive","score":0.9},{"id":"1","sentiment":"neutral","score":0.5}]`,
+			want:  []string{"1:neutral"},
+		},
+		{
+			name:  "invalid values dropped",
+			reply: `[{"id":"1","sentiment":"happy","score":0.9},{"id":"2","sentiment":"positive","score":1.5},{"id":"3","sentiment":"negative","score":0}]`,
+			want:  []string{"3:negative"},
+		},
+		{
+			name:  "duplicates keep the first",
+			reply: `[{"id":"1","sentiment":"positive","score":0.9},{"id":"1","sentiment":"negative","score":0.1}]`,
+			want:  []string{"1:positive"},
+		},
+		{name: "no array", reply: `{"id":"1"}`, wantErr: true},
+		{name: "malformed array", reply: `[{"id":"1",]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := parseBatchResponse(tt.reply, items)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseBatchResponse() = %v, want error", results)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, r := range results {
+				got = append(got, r.ID+":"+r.Sentiment)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("parseBatchResponse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildBatchPrompt(t *testing.T) {
+	long := strings.Repeat("a", maxBatchTextLen+100)
+	prompt, err := buildBatchPrompt([]Item{
+		{ID: "pr1-0", Text: "**Looks good** see https://example.com"},
+		{ID: "pr1-1", Text: long},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(prompt, `{"id":"pr1-0","text":"Looks good see"}`) {
+		t.Errorf("prompt does not contain the cleaned first message:\n%s", prompt)
+	}
+	if strings.Contains(prompt, long) || !strings.Contains(prompt, long[:maxBatchTextLen]) {
+		t.Error("long message was not truncated to maxBatchTextLen")
+	}
+}
@@ -0,0 +1,150 @@
+package sentiment
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"repo-doc/internal/cache"
+)
+
+// Cached wraps a backend and remembers its results in a cache.Store, keyed
+// by provider, model, server and the cleaned message text. Re-running on
+// overlapping PRs only classifies new messages, and repeated reports stay
+// stable instead of drifting with LLM non-determinism.
+type Cached struct {
+	Sentiment
+	store  *cache.Store
+	hits   atomic.Int64
+	misses atomic.Int64
+
+	// unanswered counts keys already counted as misses by AnalyzeBatch
+	// but missing from the batch reply, so the per-message retry in
+	// AnalyzeAll does not count them again.
+	mu         sync.Mutex
+	unanswered map[string]int
+}
+
+// endpointer is implemented by backends that talk to a configurable
+// server, whose address becomes part of the cache key.
+type endpointer interface {
+	Endpoint() string
+}
+
+// NewCached returns s backed by store.
+func NewCached(s Sentiment, store *cache.Store) *Cached {
+	return &Cached{Sentiment: s, store: store}
+}
+
+// Stats returns how many messages were served from the cache and how many
+// were not found in it.
+func (c *Cached) Stats() (hits, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+func (c *Cached) key(text string) string {
+	var endpoint string
+	if e, ok := c.Sentiment.(endpointer); ok {
+		endpoint = e.Endpoint()
+	}
+	return c.Name() + "\x00" + c.Model() + "\x00" + endpoint + "\x00" + CleanText(text)
+}
+
+// countMiss records a miss for text unless AnalyzeBatch already did.
+func (c *Cached) countMiss(text string) {
+	key := c.key(text)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unanswered[key] > 0 {
+		c.unanswered[key]--
+		if c.unanswered[key] == 0 {
+			delete(c.unanswered, key)
+		}
+		return
+	}
+	c.misses.Add(1)
+}
+
+func (c *Cached) get(text string) (*SentimentResponse, bool) {
+	data, ok := c.store.Get(c.key(text))
+	if !ok {
+		return nil, false
+	}
+
+	var result SentimentResponse
+	if err := json.Unmarshal(data, &result); err != nil || validate(&result) != nil {
+		return nil, false
+	}
+	c.hits.Add(1)
+	return &result, true
+}
+
+func (c *Cached) put(text string, result *SentimentResponse) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	if err := c.store.Put(c.key(text), data); err != nil {
+		log.Printf("Warning: failed to write sentiment cache entry: %v", err)
+	}
+}
+
+func (c *Cached) Analyze(ctx context.Context, text string) (*SentimentResponse, error) {
+	if result, ok := c.get(text); ok {
+		return result, nil
+	}
+
+	c.countMiss(text)
+	result, err := c.Sentiment.Analyze(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	c.put(text, result)
+	return result, nil
+}
+
+// AnalyzeBatch answers cached items directly and forwards the rest to the
+// wrapped backend if it supports batching. Items it cannot answer are left
+// for AnalyzeAll's per-message fallback.
+func (c *Cached) AnalyzeBatch(ctx context.Context, items []Item) ([]BatchResult, error) {
+	var results []BatchResult
+	var misses []Item
+	texts := make(map[string]string, len(items))
+
+	for _, item := range items {
+		if result, ok := c.get(item.Text); ok {
+			results = append(results, BatchResult{ID: item.ID, Sentiment: result.Sentiment, Score: result.Score})
+			continue
+		}
+		misses = append(misses, item)
+		texts[item.ID] = item.Text
+	}
+
+	b, ok := c.Sentiment.(Batcher)
+	if !ok || len(misses) == 0 {
+		return results, nil
+	}
+
+	c.misses.Add(int64(len(misses)))
+	fresh, err := b.AnalyzeBatch(ctx, misses)
+	answered := make(map[string]bool, len(fresh))
+	for _, r := range fresh {
+		c.put(texts[r.ID], &SentimentResponse{Sentiment: r.Sentiment, Score: r.Score})
+		answered[r.ID] = true
+	}
+
+	c.mu.Lock()
+	for _, item := range misses {
+		if !answered[item.ID] {
+			if c.unanswered == nil {
+				c.unanswered = make(map[string]int)
+			}
+			c.unanswered[c.key(item.Text)]++
+		}
+	}
+	c.mu.Unlock()
+
+	return append(results, fresh...), err
+}
@@ -0,0 +1,56 @@
+package sentiment
+
+import (
+	"context"
+	"testing"
+
+	"repo-doc/internal/cache"
+)
+
+func openStore(t *testing.T) *cache.Store {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	store, err := cache.Open("sentiment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestCachedCountsEachMissOnce(t *testing.T) {
+	store := openStore(t)
+	items := []Item{
+		{ID: "1", Text: "good change"},
+		{ID: "2", Text: "bad change"},
+		{ID: "3", Text: "good idea"},
+	}
+
+	c := NewCached(&fakeBatcher{skip: map[string]bool{"bad change": true}}, store)
+	results, _ := AnalyzeAll(context.Background(), c, items, 10)
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	if hits, misses := c.Stats(); hits != 0 || misses != 3 {
+		t.Errorf("first run Stats() = %d, %d; want 0, 3", hits, misses)
+	}
+
+	c = NewCached(&fakeBatcher{}, store)
+	AnalyzeAll(context.Background(), c, items, 10)
+	if hits, misses := c.Stats(); hits != 3 || misses != 0 {
+		t.Errorf("second run Stats() = %d, %d; want 3, 0", hits, misses)
+	}
+}
+
+func TestCachedKeyIncludesEndpoint(t *testing.T) {
+	store := openStore(t)
+	local := NewCached(NewOllama(Config{BaseURL: "http://localhost:11434", Model: "llama3"}), store)
+	remote := NewCached(NewOllama(Config{BaseURL: "http://gpu-box:11434/", Model: "llama3"}), store)
+
+	local.put("looks good", &SentimentResponse{Sentiment: "positive", Score: 0.9})
+	if _, ok := remote.get("looks good"); ok {
+		t.Error("result cached for one server was served for another")
+	}
+	if _, ok := local.get("looks good"); !ok {
+		t.Error("result was not served from the cache for the same server")
+	}
+}
@@ -0,0 +1,126 @@
+package sentiment
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"repo-doc/internal/retry"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+const defaultGeminiModel = "gemini-1.5-pro-latest"
+
+// Gemini classifies text with Google's Gemini API. One client is shared
+// by every request; call Close when done.
+type Gemini struct {
+	client  *genai.Client
+	model   *genai.GenerativeModel
+	name    string
+	retries int
+}
+
+// NewGemini returns a Gemini backend using cfg.Model. GEMINI_API_KEY must
+// be set.
+func NewGemini(ctx context.Context, cfg Config) (*Gemini, error) {
+	model := cfg.Model
+	apiKey := os.Getenv("GEMINI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("GEMINI_API_KEY environment variable is required for the gemini provider. Please set it in .env file or environment variables")
+	}
+	if model == "" {
+		model = defaultGeminiModel
+	}
+
+	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %v", err)
+	}
+
+	m := client.GenerativeModel(model)
+
+	temp := float32(0.2)
+	topP := float32(0.9)
+	topK := int32(40)
+	maxTokens := int32(4096)
+
+	m.Temperature = &temp
+	m.TopP = &topP
+	m.TopK = &topK
+	m.MaxOutputTokens = &maxTokens
+
+	return &Gemini{client: client, model: m, name: model, retries: cfg.Retries}, nil
+}
+
+func (g *Gemini) Name() string  { return "gemini" }
+func (g *Gemini) Model() string { return g.name }
+func (g *Gemini) Close() error  { return g.client.Close() }
+
+func (g *Gemini) Analyze(ctx context.Context, text string) (*SentimentResponse, error) {
+	cleanText := CleanText(text)
+	if cleanText == "" {
+		return &SentimentResponse{Sentiment: "neutral", Score: 0.5}, nil
+	}
+
+	responseText, err := g.generate(ctx, buildPrompt(cleanText))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := parseResponse(responseText)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Analysis result - Sentiment: %s, Score: %.2f", result.Sentiment, result.Score)
+	return result, nil
+}
+
+func (g *Gemini) AnalyzeBatch(ctx context.Context, items []Item) ([]BatchResult, error) {
+	prompt, err := buildBatchPrompt(items)
+	if err != nil {
+		return nil, err
+	}
+
+	responseText, err := g.generate(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	return parseBatchResponse(responseText, items)
+}
+
+// generate sends prompt to the configured model and returns the reply text.
+// Transient API failures are retried with backoff.
+func (g *Gemini) generate(ctx context.Context, prompt string) (string, error) {
+	log.Printf("Sending request to model with prompt length: %d", len(prompt))
+
+	var resp *genai.GenerateContentResponse
+	err := retry.Do(ctx, g.retries, func() error {
+		var err error
+		resp, err = g.model.GenerateContent(ctx, genai.Text(prompt))
+		if err != nil && retry.Transient(err) {
+			log.Printf("Gemini request failed (%v), retrying", err)
+		}
+		return err
+	})
+	if err != nil {
+		log.Printf("Error details: %v", err)
+		return "", fmt.Errorf("failed to generate content: %v", err)
+	}
+
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return "", fmt.Errorf("no content in response")
+	}
+	responseText := ""
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if textPart, ok := part.(genai.Text); ok {
+			responseText += string(textPart)
+		}
+	}
+
+	log.Printf("Raw response: %s", responseText)
+	return responseText, nil
+}
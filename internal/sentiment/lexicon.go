@@ -0,0 +1,134 @@
+package sentiment
+
+import (
+	"context"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	// negationScope is how many tokens after a negator have their valence flipped.
+	negationScope = 4
+	// negationFactor dampens flipped valences: "not great" is milder than "bad".
+	negationFactor = -0.75
+	// normalizationAlpha controls how quickly the summed valence saturates.
+	normalizationAlpha = 15.0
+)
+
+// Lexicon is an offline, rule-based backend tuned for code-review language.
+// It needs no network access or API key.
+type Lexicon struct{}
+
+// NewLexicon returns the built-in lexicon backend.
+func NewLexicon() *Lexicon {
+	return &Lexicon{}
+}
+
+func (l *Lexicon) Name() string  { return "lexicon" }
+func (l *Lexicon) Model() string { return "builtin-v1" }
+func (l *Lexicon) Close() error  { return nil }
+
+func (l *Lexicon) Analyze(ctx context.Context, text string) (*SentimentResponse, error) {
+	tokens := tokenize(CleanText(text))
+	if len(tokens) == 0 {
+		return &SentimentResponse{Sentiment: "neutral", Score: 0.5}, nil
+	}
+
+	total := 0.0
+	negated := 0
+	butSeen := false
+	var valences []float64
+	var afterBut []bool
+
+	for i, tok := range tokens {
+		if negators[tok] {
+			negated = negationScope
+			continue
+		}
+		if tok == "but" || tok == "however" || tok == "although" {
+			butSeen = true
+			continue
+		}
+
+		v, ok := lexicon[tok]
+		if !ok {
+			if negated > 0 {
+				negated--
+			}
+			continue
+		}
+
+		if i > 0 {
+			if boost, ok := intensifiers[tokens[i-1]]; ok {
+				v *= boost
+			}
+		}
+		if negated > 0 {
+			v *= negationFactor
+			negated--
+		}
+
+		valences = append(valences, v)
+		afterBut = append(afterBut, butSeen)
+	}
+
+	// Contrast: in "looks good but this breaks X" the clause after "but"
+	// carries the reviewer's actual verdict.
+	for i, v := range valences {
+		if butSeen {
+			if afterBut[i] {
+				v *= 1.5
+			} else {
+				v *= 0.5
+			}
+		}
+		total += v
+	}
+
+	compound := total / math.Sqrt(total*total+normalizationAlpha)
+	score := math.Round((compound+1)/2*100) / 100
+
+	label := "neutral"
+	switch {
+	case compound >= 0.2:
+		label = "positive"
+	case compound <= -0.2:
+		label = "negative"
+	}
+
+	return &SentimentResponse{Sentiment: label, Score: score}, nil
+}
+
+// tokenize lowercases text and splits it into words, keeping apostrophes
+// and "+1"-style tokens intact. Symbol runes such as emoji become tokens
+// of their own.
+func tokenize(text string) []string {
+	var tokens []string
+	var b strings.Builder
+
+	flush := func() {
+		if b.Len() > 0 {
+			tokens = append(tokens, strings.Trim(b.String(), "'"))
+			b.Reset()
+		}
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '’' || r == '+':
+			if r == '’' {
+				r = '\''
+			}
+			b.WriteRune(r)
+		case unicode.Is(unicode.So, r):
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return tokens
+}
@@ -0,0 +1,42 @@
+package sentiment
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLexiconReviewSentences(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"LGTM, thanks!", "positive"},
+		{"Looks good to me", "positive"},
+		{"Nice catch, thanks for fixing this", "positive"},
+		{"It works now, thank you", "positive"},
+		{"Tests pass locally and the change is much cleaner", "positive"},
+		{"This doesn't work", "negative"},
+		{"This won't work for large repos", "negative"},
+		{"this breaks the build", "negative"},
+		{"This broke the release pipeline", "negative"},
+		{"The tests still fail on Windows", "negative"},
+		{"It hangs when the input is empty", "negative"},
+		{"This doesn't compile on Go 1.21", "negative"},
+		{"This is not correct", "negative"},
+		{"Looks good, but this breaks backwards compatibility", "negative"},
+		{"Can you rename this variable?", "neutral"},
+		{"Please add a test for this case", "neutral"},
+		{"Moved the helper into util.go", "neutral"},
+	}
+
+	l := NewLexicon()
+	for _, tt := range tests {
+		got, err := l.Analyze(context.Background(), tt.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Sentiment != tt.want {
+			t.Errorf("Analyze(%q) = %s (%.2f), want %s", tt.text, got.Sentiment, got.Score, tt.want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package sentiment
+
+// lexicon maps lowercase tokens to a valence between -4 (very negative)
+// and +4 (very positive). Entries are weighted for PR review discussions:
+// "nit" and "typo" are mild, "blocker" and "regression" are strong.
+var lexicon = map[string]float64{
+	// Approval and review shorthand
+	"lgtm":      3.0,
+	"sgtm":      2.5,
+	"+1":        1.5,
+	"approve":   2.0,
+	"approved":  2.0,
+	"approving": 2.0,
+	"ship":      1.5,
+	"shipit":    2.5,
+	"merge":     0.5,
+	"merged":    0.5,
+	"ack":       1.0,
+	"nit":       -0.3,
+	"nits":      -0.3,
+	"nitpick":   -0.3,
+	"typo":      -0.3,
+	"minor":     0.2,
+	"optional":  0.2,
+
+	// Gratitude and praise
+	"thanks":      2.0,
+	"thank":       2.0,
+	"thx":         1.5,
+	"ty":          1.5,
+	"appreciate":  2.0,
+	"appreciated": 2.0,
+	"great":       3.0,
+	"good":        1.9,
+	"nice":        2.0,
+	"awesome":     3.1,
+	"excellent":   3.2,
+	"amazing":     3.0,
+	"perfect":     2.8,
+	"love":        3.0,
+	"cool":        1.5,
+	"clean":       1.5,
+	"cleaner":     1.6,
+	"neat":        1.8,
+	"elegant":     2.5,
+	"simple":      1.0,
+	"simpler":     1.3,
+	"readable":    1.5,
+	"helpful":     1.8,
+	"useful":      1.6,
+	"better":      1.5,
+	"improvement": 1.8,
+	"improves":    1.5,
+	"improved":    1.5,
+	"solid":       1.8,
+	"fixed":       1.5,
+	"fixes":       1.0,
+	"work":        1.5,
+	"works":       1.5,
+	"worked":      1.5,
+	"working":     1.0,
+	"compile":     1.2,
+	"compiles":    1.2,
+	"builds":      1.0,
+	"pass":        1.2,
+	"passes":      1.2,
+	"passed":      1.2,
+	"passing":     1.2,
+	"helps":       1.2,
+	"green":       1.0,
+	"agree":       1.5,
+	"agreed":      1.5,
+	"welcome":     1.5,
+	"glad":        2.0,
+	"happy":       2.5,
+	"congrats":    2.5,
+	"kudos":       2.5,
+	"yes":         1.0,
+	"correct":     1.5,
+
+	// Problems and defects
+	"blocker":       -2.5,
+	"blocking":      -2.0,
+	"regression":    -2.0,
+	"regressions":   -2.0,
+	"regressed":     -2.0,
+	"bug":           -1.2,
+	"bugs":          -1.2,
+	"buggy":         -1.8,
+	"broken":        -2.2,
+	"breaks":        -2.0,
+	"break":         -1.5,
+	"breaking":      -1.8,
+	"broke":         -2.0,
+	"breakage":      -2.0,
+	"crash":         -2.5,
+	"crashes":       -2.5,
+	"crashed":       -2.5,
+	"panic":         -2.2,
+	"panics":        -2.2,
+	"panicked":      -2.2,
+	"hang":          -1.5,
+	"hangs":         -1.8,
+	"fail":          -2.0,
+	"fails":         -2.0,
+	"failing":       -2.0,
+	"failed":        -2.0,
+	"failure":       -2.0,
+	"flaky":         -1.5,
+	"leak":          -2.0,
+	"leaks":         -2.0,
+	"leaking":       -2.0,
+	"race":          -1.5,
+	"deadlock":      -2.2,
+	"slow":          -1.2,
+	"slower":        -1.2,
+	"revert":        -1.5,
+	"reverted":      -1.5,
+	"wrong":         -2.0,
+	"incorrect":     -1.8,
+	"error":         -1.0,
+	"errors":        -1.0,
+	"issue":         -0.5,
+	"problem":       -1.5,
+	"problems":      -1.5,
+	"concern":       -1.2,
+	"concerns":      -1.2,
+	"concerned":     -1.5,
+	"worried":       -1.5,
+	"confusing":     -1.5,
+	"confused":      -1.3,
+	"unclear":       -1.2,
+	"hack":          -1.0,
+	"hacky":         -1.5,
+	"ugly":          -2.0,
+	"messy":         -1.8,
+	"smell":         -1.2,
+	"duplicate":     -0.8,
+	"duplicated":    -0.8,
+	"unnecessary":   -1.0,
+	"unused":        -0.5,
+	"missing":       -1.0,
+	"insecure":      -2.5,
+	"vulnerable":    -2.5,
+	"vulnerability": -2.5,
+	"dangerous":     -2.2,
+	"risky":         -1.5,
+	"bad":           -2.5,
+	"terrible":      -3.2,
+	"awful":         -3.0,
+	"horrible":      -3.1,
+	"worse":         -2.1,
+	"worst":         -3.1,
+	"hate":          -2.7,
+	"annoying":      -2.0,
+	"frustrating":   -2.2,
+	"disagree":      -1.5,
+	"reject":        -2.0,
+	"rejected":      -2.0,
+	"nack":          -2.0,
+	"unacceptable":  -3.0,
+	"stale":         -0.8,
+	"abandoned":     -1.5,
+	"sorry":         -0.5,
+
+	// Emoji
+	"👍": 2.0,
+	"👎": -2.0,
+	"🎉": 2.5,
+	"🚀": 2.0,
+	"❤": 2.5,
+	"😕": -1.5,
+	"😞": -2.0,
+	"😡": -3.0,
+}
+
+// negators flip the valence of the next few lexicon hits.
+var negators = map[string]bool{
+	"not": true, "no": true, "never": true, "none": true, "nothing": true,
+	"without": true, "hardly": true, "barely": true, "nor": true,
+	"don't": true, "dont": true, "doesn't": true, "doesnt": true,
+	"didn't": true, "didnt": true, "isn't": true, "isnt": true,
+	"aren't": true, "arent": true, "wasn't": true, "wasnt": true,
+	"won't": true, "wont": true, "can't": true, "cant": true,
+	"cannot": true, "shouldn't": true, "shouldnt": true,
+	"wouldn't": true, "wouldnt": true, "couldn't": true, "couldnt": true,
+}
+
+// intensifiers scale the valence of the lexicon word that follows them.
+var intensifiers = map[string]float64{
+	"very":       1.3,
+	"really":     1.3,
+	"extremely":  1.5,
+	"super":      1.3,
+	"so":         1.2,
+	"totally":    1.3,
+	"completely": 1.4,
+	"absolutely": 1.4,
+	"incredibly": 1.5,
+	"highly":     1.3,
+	"definitely": 1.2,
+	"quite":      1.1,
+	"pretty":     1.1,
+	"seriously":  1.3,
+	"slightly":   0.6,
+	"somewhat":   0.7,
+	"kinda":      0.7,
+	"little":     0.7,
+	"minor":      0.6,
+}
@@ -0,0 +1,107 @@
+package sentiment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// buildPrompt returns the classification prompt shared by all LLM backends.
+func buildPrompt(cleanText string) string {
+	return fmt.Sprintf(`Analyze the sentiment of this GitHub PR discussion text and respond with a JSON object containing "sentiment" (one of: "positive", "neutral", "negative") and "score" (0.0 to 1.0, where 0 is most negative and 1 is most positive).
+
+Text to analyze:
+%s
+
+Respond with only the JSON object, nothing else.`, cleanText)
+}
+
+// parseResponse extracts and validates the JSON object in an LLM reply.
+func parseResponse(responseText string) (*SentimentResponse, error) {
+	var result SentimentResponse
+
+	jsonStart := strings.Index(responseText, "{")
+	jsonEnd := strings.LastIndex(responseText, "}")
+	if jsonStart == -1 || jsonEnd == -1 || jsonEnd < jsonStart {
+		return nil, fmt.Errorf("invalid JSON response: %s", responseText)
+	}
+
+	jsonStr := responseText[jsonStart : jsonEnd+1]
+	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
+		log.Printf("Failed to parse JSON response: %v\nResponse: %s", err, responseText)
+		return nil, fmt.Errorf("failed to parse JSON response: %v", err)
+	}
+
+	if err := validate(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func validate(result *SentimentResponse) error {
+	switch result.Sentiment {
+	case "positive", "neutral", "negative":
+	default:
+		return fmt.Errorf("invalid sentiment value: %s", result.Sentiment)
+	}
+	if result.Score < 0 || result.Score > 1 {
+		return fmt.Errorf("score out of range: %f", result.Score)
+	}
+	return nil
+}
+
+// postJSON sends in as a JSON POST body and decodes the JSON reply into out.
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &statusError{url: url, status: resp.Status, code: resp.StatusCode, body: strings.TrimSpace(string(respBody))}
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+	return nil
+}
+
+// statusError is a non-2xx reply from an LLM server. HTTPCode lets the
+// retry package recognise transient failures.
+type statusError struct {
+	url    string
+	status string
+	code   int
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s returned %s: %s", e.url, e.status, e.body)
+}
+
+func (e *statusError) HTTPCode() int { return e.code }
@@ -0,0 +1,114 @@
+package sentiment
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"repo-doc/internal/retry"
+)
+
+const (
+	defaultOllamaURL   = "http://localhost:11434"
+	defaultOllamaModel = "llama3"
+)
+
+// Ollama classifies text with a local Ollama server's /api/generate endpoint.
+type Ollama struct {
+	baseURL string
+	model   string
+	client  *http.Client
+	retries int
+}
+
+// NewOllama returns an Ollama backend for cfg.BaseURL and cfg.Model,
+// defaulting to a llama3 server on localhost.
+func NewOllama(cfg Config) *Ollama {
+	baseURL, model := cfg.BaseURL, cfg.Model
+	if baseURL == "" {
+		baseURL = defaultOllamaURL
+	}
+	if model == "" {
+		model = defaultOllamaModel
+	}
+	return &Ollama{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		model:   model,
+		client:  &http.Client{Timeout: 120 * time.Second},
+		retries: cfg.Retries,
+	}
+}
+
+func (o *Ollama) Name() string     { return "ollama" }
+func (o *Ollama) Model() string    { return o.model }
+func (o *Ollama) Endpoint() string { return o.baseURL }
+func (o *Ollama) Close() error     { return nil }
+
+type generateRequest struct {
+	Model   string             `json:"model"`
+	Prompt  string             `json:"prompt"`
+	Stream  bool               `json:"stream"`
+	Format  string             `json:"format,omitempty"`
+	Options map[string]float64 `json:"options,omitempty"`
+}
+
+type generateResponse struct {
+	Response string `json:"response"`
+}
+
+func (o *Ollama) Analyze(ctx context.Context, text string) (*SentimentResponse, error) {
+	cleanText := CleanText(text)
+	if cleanText == "" {
+		return &SentimentResponse{Sentiment: "neutral", Score: 0.5}, nil
+	}
+
+	responseText, err := o.generate(ctx, buildPrompt(cleanText))
+	if err != nil {
+		return nil, err
+	}
+	return parseResponse(responseText)
+}
+
+func (o *Ollama) AnalyzeBatch(ctx context.Context, items []Item) ([]BatchResult, error) {
+	prompt, err := buildBatchPrompt(items)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ollama's "json" format mode forces a top-level object, so batch
+	// prompts are sent without it.
+	responseText, err := o.generateWithFormat(ctx, prompt, "")
+	if err != nil {
+		return nil, err
+	}
+	return parseBatchResponse(responseText, items)
+}
+
+// generate runs a non-streaming completion and returns the reply text.
+func (o *Ollama) generate(ctx context.Context, prompt string) (string, error) {
+	return o.generateWithFormat(ctx, prompt, "json")
+}
+
+func (o *Ollama) generateWithFormat(ctx context.Context, prompt, format string) (string, error) {
+	req := generateRequest{
+		Model:   o.model,
+		Prompt:  prompt,
+		Stream:  false,
+		Format:  format,
+		Options: map[string]float64{"temperature": 0.2},
+	}
+
+	log.Printf("Sending request to model with prompt length: %d", len(prompt))
+	var resp generateResponse
+	err := retry.Do(ctx, o.retries, func() error {
+		return postJSON(ctx, o.client, o.baseURL+"/api/generate", nil, req, &resp)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("Raw response: %s", resp.Response)
+	return resp.Response, nil
+}
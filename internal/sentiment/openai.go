@@ -0,0 +1,120 @@
+package sentiment
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"repo-doc/internal/retry"
+)
+
+// OpenAI classifies text with any OpenAI-compatible /v1/chat/completions
+// endpoint, such as vLLM, llama.cpp server or LocalAI.
+type OpenAI struct {
+	baseURL string
+	model   string
+	apiKey  string
+	client  *http.Client
+	retries int
+}
+
+// NewOpenAI returns an OpenAI-compatible backend for cfg.BaseURL and
+// cfg.Model. OPENAI_API_KEY is sent as a bearer token when set.
+func NewOpenAI(cfg Config) (*OpenAI, error) {
+	baseURL, model := cfg.BaseURL, cfg.Model
+	if baseURL == "" {
+		return nil, fmt.Errorf("--sentiment-url is required for the openai provider")
+	}
+	if model == "" {
+		return nil, fmt.Errorf("--sentiment-model is required for the openai provider")
+	}
+	return &OpenAI{
+		baseURL: strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/v1"),
+		model:   model,
+		apiKey:  os.Getenv("OPENAI_API_KEY"),
+		client:  &http.Client{Timeout: 60 * time.Second},
+		retries: cfg.Retries,
+	}, nil
+}
+
+func (o *OpenAI) Name() string     { return "openai" }
+func (o *OpenAI) Model() string    { return o.model }
+func (o *OpenAI) Endpoint() string { return o.baseURL }
+func (o *OpenAI) Close() error     { return nil }
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (o *OpenAI) Analyze(ctx context.Context, text string) (*SentimentResponse, error) {
+	cleanText := CleanText(text)
+	if cleanText == "" {
+		return &SentimentResponse{Sentiment: "neutral", Score: 0.5}, nil
+	}
+
+	responseText, err := o.complete(ctx, buildPrompt(cleanText))
+	if err != nil {
+		return nil, err
+	}
+	return parseResponse(responseText)
+}
+
+func (o *OpenAI) AnalyzeBatch(ctx context.Context, items []Item) ([]BatchResult, error) {
+	prompt, err := buildBatchPrompt(items)
+	if err != nil {
+		return nil, err
+	}
+
+	responseText, err := o.complete(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	return parseBatchResponse(responseText, items)
+}
+
+// complete sends prompt as a single user message and returns the reply text.
+func (o *OpenAI) complete(ctx context.Context, prompt string) (string, error) {
+	headers := map[string]string{}
+	if o.apiKey != "" {
+		headers["Authorization"] = "Bearer " + o.apiKey
+	}
+
+	req := chatRequest{
+		Model:       o.model,
+		Messages:    []chatMessage{{Role: "user", Content: prompt}},
+		Temperature: 0.2,
+	}
+
+	log.Printf("Sending request to model with prompt length: %d", len(prompt))
+	var resp chatResponse
+	err := retry.Do(ctx, o.retries, func() error {
+		return postJSON(ctx, o.client, o.baseURL+"/v1/chat/completions", headers, req, &resp)
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no content in response")
+	}
+
+	responseText := resp.Choices[0].Message.Content
+	log.Printf("Raw response: %s", responseText)
+	return responseText, nil
+}
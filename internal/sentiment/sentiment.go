@@ -0,0 +1,91 @@
+package sentiment
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// SentimentResponse is the classification returned by every backend.
+type SentimentResponse struct {
+	Sentiment string  `json:"sentiment"`
+	Score     float64 `json:"score"`
+}
+
+// Sentiment is implemented by every sentiment backend usable by `health`.
+type Sentiment interface {
+	// Name returns the provider identifier, e.g. "gemini".
+	Name() string
+	// Model returns the model used by the provider, if any.
+	Model() string
+	// Analyze classifies a single piece of PR discussion text.
+	Analyze(ctx context.Context, text string) (*SentimentResponse, error)
+	// Close releases any clients held by the backend.
+	Close() error
+}
+
+// Config selects and configures a sentiment backend.
+type Config struct {
+	Provider string
+	Model    string
+	// BaseURL is the server address for the openai and ollama providers.
+	BaseURL string
+	// Retries is how many times transient LLM failures are retried.
+	Retries int
+}
+
+// Providers lists the accepted values for Config.Provider.
+var Providers = []string{"auto", "gemini", "lexicon", "openai", "ollama"}
+
+// New returns the backend selected by cfg.Provider. "auto" picks Gemini
+// when GEMINI_API_KEY is set and falls back to the offline lexicon.
+func New(ctx context.Context, cfg Config) (Sentiment, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "", "auto":
+		if os.Getenv("GEMINI_API_KEY") != "" {
+			return NewGemini(ctx, cfg)
+		}
+		log.Println("GEMINI_API_KEY not set, using the offline lexicon sentiment backend")
+		return NewLexicon(), nil
+	case "gemini":
+		return NewGemini(ctx, cfg)
+	case "lexicon":
+		return NewLexicon(), nil
+	case "openai":
+		return NewOpenAI(cfg)
+	case "ollama":
+		return NewOllama(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown sentiment provider: %s. Use one of: %s", cfg.Provider, strings.Join(Providers, ", "))
+	}
+}
+
+var (
+	codeBlockRe  = regexp.MustCompile("(?s)```.*?```")
+	inlineCodeRe = regexp.MustCompile("`[^`]+`")
+	urlRe        = regexp.MustCompile(`https?://\S+`)
+	markdownRe   = regexp.MustCompile(`[#*\-_=~]+`)
+)
+
+// CleanText strips code, URLs and markdown noise before analysis.
+func CleanText(text string) string {
+	// Remove code blocks
+	text = codeBlockRe.ReplaceAllString(text, " ")
+
+	// Remove inline code
+	text = inlineCodeRe.ReplaceAllString(text, " ")
+
+	// Remove URLs
+	text = urlRe.ReplaceAllString(text, " ")
+
+	// Remove markdown headers, lists, etc.
+	text = markdownRe.ReplaceAllString(text, " ")
+
+	// Remove extra whitespace
+	text = strings.Join(strings.Fields(text), " ")
+
+	return strings.TrimSpace(text)
+}